@@ -1,6 +1,7 @@
 package miser
 
 import (
+	"strings"
 	"sync"
 	"time"
 )
@@ -22,6 +23,16 @@ type Account struct {
 
 func (a *Account) isClosed() bool { return !a.ClosedAt.IsZero() }
 
+// Full name of account: its type and name, e.g. Expense:Food:Groceries,
+// names containing colons form a tree of accounts.
+func (a *Account) FullName() string { return string(a.Type) + ":" + string(a.Name) }
+
+// Check if account is the given one (by full name, case insensitive) or belongs to its subtree.
+func (a *Account) Under(name string) bool {
+	full, name := strings.ToLower(a.FullName()), strings.ToLower(name)
+	return full == name || strings.HasPrefix(full, name+":")
+}
+
 type AccountRegistry struct {
 	items  map[ID]Account
 	queued map[ID]Account
@@ -22,7 +22,7 @@ func TestCreateAccount(t *testing.T) {
 		// Create service:
 		l := CreateLedger(ar, br, tr, cr, tg, tm)
 
-		acc, err := l.CreateAccount("Deposit", Asset, "deposit account", "USD", time.Now(), 0.00)
+		acc, err := l.CreateAccount("Deposit", Asset, "deposit account", "USD", time.Now(), "0")
 		if err != nil {
 			t.Fatal(err)
 		}
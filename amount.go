@@ -0,0 +1,225 @@
+package miser
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+)
+
+// Number of fraction digits an amount can hold (wei of ETH).
+const AmountDigits = 18
+
+// Amount is an exact decimal value with up to AmountDigits fraction digits.
+// It is backed by arbitrary-precision integer, so the arithmetic never overflows.
+//
+// Amount is value object, it is immutable: arithmetic methods return
+// a new amount. Use Cmp or Equal instead of == for comparison.
+type Amount struct {
+	v *big.Int // in units of 10^-AmountDigits, nil means zero
+}
+
+var ErrAmountSyntax = errors.New("invalid amount")
+
+var (
+	ten       = big.NewInt(10)
+	amountOne = new(big.Int).Exp(ten, big.NewInt(AmountDigits), nil)
+	legacyOne = new(big.Int).Exp(ten, big.NewInt(AmountDigits-6), nil) // unit of millionths
+)
+
+// Amount of given number of millionths, the format of amounts before arbitrary precision.
+func AmountFromMillionths(v int64) Amount {
+	return Amount{new(big.Int).Mul(big.NewInt(v), legacyOne)}
+}
+
+// Amount of given integer number of units, e.g. AmountFromInt(5) is 5.00.
+func AmountFromInt(v int64) Amount {
+	return Amount{new(big.Int).Mul(big.NewInt(v), amountOne)}
+}
+
+// Parse decimal string (e.g. "1234.56", "-0.5", "+3") exactly and round it
+// to given number of fraction digits (minor units of currency), half away from zero.
+func ParseAmount(s string, digits int) (Amount, error) {
+	digits = min(max(digits, 0), AmountDigits)
+
+	s = strings.TrimSpace(s)
+	neg := false
+	if s != "" && (s[0] == '-' || s[0] == '+') {
+		neg = s[0] == '-'
+		s = s[1:]
+	}
+
+	intPart, fracPart, _ := strings.Cut(s, ".")
+	if intPart == "" && fracPart == "" {
+		return Amount{}, fmt.Errorf("%w: %q", ErrAmountSyntax, s)
+	}
+	for _, r := range intPart + fracPart {
+		if r < '0' || r > '9' {
+			return Amount{}, fmt.Errorf("%w: %q", ErrAmountSyntax, s)
+		}
+	}
+
+	// round half away from zero by the first dropped digit:
+	roundUp := len(fracPart) > digits && fracPart[digits] >= '5'
+	if len(fracPart) > digits {
+		fracPart = fracPart[:digits]
+	}
+	fracPart += strings.Repeat("0", AmountDigits-len(fracPart))
+
+	v, ok := new(big.Int).SetString(intPart+fracPart, 10)
+	if !ok {
+		return Amount{}, fmt.Errorf("%w: %q", ErrAmountSyntax, s)
+	}
+	if roundUp {
+		v.Add(v, unit(digits))
+	}
+	if neg {
+		v.Neg(v)
+	}
+	return Amount{v}, nil
+}
+
+// Parse decimal string with full precision, panic on error (useful for constants).
+func MustParseAmount(s string) Amount {
+	a, err := ParseAmount(s, AmountDigits)
+	if err != nil {
+		panic(err)
+	}
+	return a
+}
+
+func (a Amount) int() *big.Int {
+	if a.v == nil {
+		return new(big.Int)
+	}
+	return a.v
+}
+
+func (a Amount) Add(b Amount) Amount { return Amount{new(big.Int).Add(a.int(), b.int())} }
+func (a Amount) Sub(b Amount) Amount { return Amount{new(big.Int).Sub(a.int(), b.int())} }
+func (a Amount) Neg() Amount         { return Amount{new(big.Int).Neg(a.int())} }
+func (a Amount) Abs() Amount         { return Amount{new(big.Int).Abs(a.int())} }
+
+func (a Amount) Cmp(b Amount) int      { return a.int().Cmp(b.int()) }
+func (a Amount) Equal(b Amount) bool   { return a.Cmp(b) == 0 }
+func (a Amount) Sign() int             { return a.int().Sign() }
+func (a Amount) IsZero() bool          { return a.Sign() == 0 }
+func (a Amount) Rat() *big.Rat         { return new(big.Rat).SetFrac(a.int(), amountOne) }
+func (a Amount) Mul(r *big.Rat) Amount { return amountFromRat(new(big.Rat).Mul(a.Rat(), r)) }
+
+// Round amount to given number of fraction digits, half away from zero.
+func (a Amount) Round(digits int) Amount {
+	if digits >= AmountDigits {
+		return a
+	}
+	u := unit(max(digits, 0))
+	q, r := new(big.Int).QuoRem(a.int(), u, new(big.Int))
+	if new(big.Int).Abs(new(big.Int).Lsh(r, 1)).Cmp(u) >= 0 {
+		q.Add(q, big.NewInt(int64(a.Sign())))
+	}
+	return Amount{q.Mul(q, u)}
+}
+
+// Split amount into n parts of given number of fraction digits, the sum of parts is
+// exactly the amount: the remaining minor units go one by one to the first parts,
+// e.g. 100.00 split into 3 parts is 33.34, 33.33, 33.33.
+func (a Amount) Split(n, digits int) []Amount {
+	if n <= 0 {
+		return nil
+	}
+
+	u := unit(min(max(digits, 0), AmountDigits))
+	units, rest := new(big.Int).QuoRem(a.int(), u, new(big.Int))
+	q, r := new(big.Int).QuoRem(units, big.NewInt(int64(n)), new(big.Int))
+
+	parts := make([]Amount, n)
+	extra := int(new(big.Int).Abs(r).Int64())
+	for i := range parts {
+		v := new(big.Int).Set(q)
+		if i < extra {
+			v.Add(v, big.NewInt(int64(a.Sign())))
+		}
+		parts[i] = Amount{v.Mul(v, u)}
+	}
+	parts[0].v.Add(parts[0].v, rest) // less than minor unit, if the amount is more precise
+	return parts
+}
+
+// Format amount with given number of fraction digits, e.g. "1234.50".
+func (a Amount) Format(digits int) string {
+	digits = min(max(digits, 0), AmountDigits)
+	v := a.Round(digits).int()
+
+	sign := ""
+	if v.Sign() < 0 {
+		sign = "-"
+	}
+
+	s := new(big.Int).Abs(v).String()
+	if len(s) <= AmountDigits {
+		s = strings.Repeat("0", AmountDigits+1-len(s)) + s
+	}
+	i := len(s) - AmountDigits
+	if digits == 0 {
+		return sign + s[:i]
+	}
+	return sign + s[:i] + "." + s[i:i+digits]
+}
+
+// Decimal representation of amount without trailing zeros.
+func (a Amount) String() string {
+	s := a.Format(AmountDigits)
+	if strings.Contains(s, ".") {
+		s = strings.TrimRight(strings.TrimRight(s, "0"), ".")
+	}
+	return s
+}
+
+// Nearest float64 value of amount, use it only for display or statistics.
+func (a Amount) Float() float64 {
+	f, _ := a.Rat().Float64()
+	return f
+}
+
+// Amounts are stored as decimal strings, e.g. "1234.56".
+func (a Amount) MarshalJSON() ([]byte, error) { return json.Marshal(a.String()) }
+
+// Decimal strings and integer numbers of millionths (the format
+// of journals written before arbitrary precision) are accepted.
+func (a *Amount) UnmarshalJSON(b []byte) error {
+	if len(b) > 0 && b[0] == '"' {
+		var s string
+		if err := json.Unmarshal(b, &s); err != nil {
+			return err
+		}
+		v, err := ParseAmount(s, AmountDigits)
+		if err != nil {
+			return err
+		}
+		*a = v
+		return nil
+	}
+
+	var v int64
+	if err := json.Unmarshal(b, &v); err != nil {
+		return fmt.Errorf("%w: %s", ErrAmountSyntax, b)
+	}
+	*a = AmountFromMillionths(v)
+	return nil
+}
+
+// Round rational number to amount, half away from zero.
+func amountFromRat(x *big.Rat) Amount {
+	n := new(big.Int).Mul(x.Num(), amountOne)
+	q, r := new(big.Int).QuoRem(n, x.Denom(), new(big.Int))
+	if new(big.Int).Abs(new(big.Int).Lsh(r, 1)).Cmp(x.Denom()) >= 0 {
+		q.Add(q, big.NewInt(int64(x.Sign())))
+	}
+	return Amount{q}
+}
+
+// Smallest amount with given number of fraction digits.
+func unit(digits int) *big.Int {
+	return new(big.Int).Exp(ten, big.NewInt(int64(AmountDigits-digits)), nil)
+}
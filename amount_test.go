@@ -0,0 +1,180 @@
+package miser
+
+import (
+	"encoding/json"
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestParseAmount(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		s      string
+		digits int
+		want   string
+	}{
+		{"0", 2, "0"},
+		{"1.53", 2, "1.53"},
+		{"-1.53", 2, "-1.53"},
+		{"+7", 2, "7"},
+		{".5", 2, "0.5"},
+		{"1555.125", 2, "1555.13"},
+		{"-1555.125", 2, "-1555.13"},
+		{"1555.124999", 2, "1555.12"},
+		{"112.56", 0, "113"},
+		{"112.49", 0, "112"},
+		{"0.123456789", 8, "0.12345679"},
+		{" 0.1 ", 2, "0.1"},
+		{"0.000000000000000001", 18, "0.000000000000000001"},
+		{"0.0000000000000000015", 18, "0.000000000000000002"},
+		{"123456789012345678901234567890.5", 0, "123456789012345678901234567891"},
+	}
+	for _, c := range cases {
+		v, err := ParseAmount(c.s, c.digits)
+		if err != nil {
+			t.Errorf("%q: %s", c.s, err)
+			continue
+		}
+		if v.String() != c.want {
+			t.Errorf("%q with %d digits: expected %s, got: %s", c.s, c.digits, c.want, v)
+		}
+	}
+
+	for _, s := range []string{"", "-", ".", "1,5", "1e3", "abc", "1.2.3"} {
+		if _, err := ParseAmount(s, 2); !errors.Is(err, ErrAmountSyntax) {
+			t.Errorf("%q: expected syntax error, got: %v", s, err)
+		}
+	}
+}
+
+func TestAmountFormat(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		s      string
+		digits int
+		want   string
+	}{
+		{"0", 2, "0.00"},
+		{"1.53", 2, "1.53"},
+		{"-1.53", 2, "-1.53"},
+		{"1555.125", 2, "1555.13"},
+		{"1234567", 0, "1234567"},
+		{"0.5", 0, "1"},
+		{"-0.5", 0, "-1"},
+		{"0.1234567", 6, "0.123457"},
+		{"1.000000000000000001", 18, "1.000000000000000001"},
+	}
+	for _, c := range cases {
+		if s := MustParseAmount(c.s).Format(c.digits); s != c.want {
+			t.Errorf("%s with %d digits: expected %q, got: %q", c.s, c.digits, c.want, s)
+		}
+	}
+
+	if s := (Amount{}).Format(2); s != "0.00" {
+		t.Errorf("expected zero value formatted as 0.00, got: %q", s)
+	}
+}
+
+func TestAmountArithmetic(t *testing.T) {
+	t.Parallel()
+
+	// 9.2 millions of ETH in wei overflows int64 many times:
+	a := MustParseAmount("9223372036854.775807")
+	b := MustParseAmount("0.000000000000000001")
+
+	sum := a.Add(a).Add(b)
+	if sum.String() != "18446744073709.551614000000000001" {
+		t.Errorf("unexpected sum: %s", sum)
+	}
+
+	if d := sum.Sub(a).Sub(a); !d.Equal(b) {
+		t.Errorf("expected %s, got: %s", b, d)
+	}
+
+	if a.Neg().Sign() != -1 || !a.Neg().Abs().Equal(a) {
+		t.Errorf("unexpected negation of %s", a)
+	}
+
+	if !(Amount{}).IsZero() || a.Cmp(b) != 1 || b.Cmp(a) != -1 {
+		t.Error("unexpected comparison result")
+	}
+}
+
+func TestAmountSplit(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		s      string
+		n      int
+		digits int
+		want   []string
+	}{
+		{"100", 3, 2, []string{"33.34", "33.33", "33.33"}},
+		{"-100", 3, 2, []string{"-33.34", "-33.33", "-33.33"}},
+		{"0.05", 2, 2, []string{"0.03", "0.02"}},
+		{"10", 4, 0, []string{"3", "3", "2", "2"}},
+		{"1.005", 2, 2, []string{"0.505", "0.5"}},
+		{"7", 1, 2, []string{"7"}},
+	}
+	for _, c := range cases {
+		var got []string
+		var sum Amount
+		for _, p := range MustParseAmount(c.s).Split(c.n, c.digits) {
+			got = append(got, p.String())
+			sum = sum.Add(p)
+		}
+		if !slices.Equal(got, c.want) {
+			t.Errorf("%s split into %d parts: expected %v, got: %v", c.s, c.n, c.want, got)
+		}
+		if !sum.Equal(MustParseAmount(c.s)) {
+			t.Errorf("%s split into %d parts: expected the sum of parts to be equal to it, got: %s", c.s, c.n, sum)
+		}
+	}
+
+	if parts := MustParseAmount("1").Split(0, 2); parts != nil {
+		t.Errorf("expected no parts, got: %v", parts)
+	}
+}
+
+func TestAmountJSON(t *testing.T) {
+	t.Parallel()
+
+	t.Run("decimal string", func(t *testing.T) {
+		b, err := json.Marshal(Balance{Value: MustParseAmount("0.12345678")})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !strings.Contains(string(b), `"Value":"0.12345678"`) {
+			t.Errorf("expected value as decimal string, got: %s", b)
+		}
+
+		var bl Balance
+		if err := json.Unmarshal(b, &bl); err != nil {
+			t.Fatal(err)
+		}
+		if bl.Value.String() != "0.12345678" {
+			t.Errorf("expected 0.12345678, got: %s", bl.Value)
+		}
+	})
+
+	t.Run("legacy millionths", func(t *testing.T) {
+		var bl Balance
+		if err := json.Unmarshal([]byte(`{"Account":"a","Transaction":"t","Value":1553590000}`), &bl); err != nil {
+			t.Fatal(err)
+		}
+		if bl.Value.String() != "1553.59" {
+			t.Errorf("expected 1553.59, got: %s", bl.Value)
+		}
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		var bl Balance
+		if err := json.Unmarshal([]byte(`{"Value":1.5}`), &bl); !errors.Is(err, ErrAmountSyntax) {
+			t.Errorf("expected syntax error, got: %v", err)
+		}
+	})
+}
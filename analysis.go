@@ -0,0 +1,230 @@
+package miser
+
+import (
+	"errors"
+	"io/fs"
+	"math"
+	"slices"
+	"time"
+)
+
+// AnalysisOptions are thresholds of the analysis of transactions.
+type AnalysisOptions struct {
+	// Periodic: at least MinOccurrences transactions between the same accounts,
+	// their values differ from the median by no more than AmountTolerance (fraction),
+	// intervals between them differ from the median interval by no more than
+	// IntervalTolerance (fraction), the median interval is between MinInterval and MaxInterval.
+	MinOccurrences           int
+	AmountTolerance          float64
+	IntervalTolerance        float64
+	MinInterval, MaxInterval time.Duration
+
+	// OverAverage: value of transaction is greater than the average value of Window
+	// previous transactions of its source account multiplied by AverageFactor,
+	// at least MinHistory previous transactions are required.
+	Window        int
+	AverageFactor float64
+	MinHistory    int
+}
+
+func DefaultAnalysisOptions() AnalysisOptions {
+	return AnalysisOptions{
+		MinOccurrences:    3,
+		AmountTolerance:   0.1,
+		IntervalTolerance: 0.2,
+		MinInterval:       24 * time.Hour,
+		MaxInterval:       24 * time.Hour * 400,
+		Window:            10,
+		AverageFactor:     3,
+		MinHistory:        3,
+	}
+}
+
+// AnalysisResult holds transactions tagged by the analysis.
+type AnalysisResult struct {
+	Periodic, OverAverage []ID
+}
+
+// Check options of the analysis.
+func (opts AnalysisOptions) validate() error {
+	switch {
+	case opts.MinOccurrences < 2:
+		return errors.New("periodic transactions require at least 2 occurrences")
+	case opts.AmountTolerance < 0, opts.IntervalTolerance < 0:
+		return errors.New("tolerance cannot be negative")
+	case opts.MinInterval <= 0, opts.MaxInterval < opts.MinInterval:
+		return errors.New("wrong range of intervals of periodic transactions")
+	case opts.Window < 1, opts.MinHistory < 0, opts.MinHistory > opts.Window:
+		return errors.New("wrong window of average of transactions")
+	case opts.AverageFactor <= 0:
+		return errors.New("average factor should be positive")
+	}
+	return nil
+}
+
+// Set options of the analysis of transactions made during the load.
+func (l *Ledger) SetAnalysisOptions(opts AnalysisOptions) error {
+	if err := opts.validate(); err != nil {
+		return err
+	}
+	l.analysis = opts
+	return nil
+}
+
+// Load all registries (missing journals are skipped), analyze transactions and learn spendings.
+// Set the mode of balances before the load.
+func (l *Ledger) Load() error {
+	loaders := []func() (int, error){
+		l.ar.Load, l.tr.Load, l.tg.Load, l.tm.Load,
+		l.cr.Load, l.rt.Load, l.rc.Load, l.sc.Load, l.sq.Load, l.as.Load,
+	}
+	if l.mode == JournalBalances {
+		loaders = append(loaders, l.br.Load) // derived balances do not need the journal
+	}
+	for _, load := range loaders {
+		if _, err := load(); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return err
+		}
+	}
+	if _, err := l.Analyze(l.analysis); err != nil {
+		return err
+	}
+	l.LearnSpendings()
+	return nil
+}
+
+// Analyze transactions: tag recurring payments as Periodic and transactions
+// far above the rolling average of their source account as OverAverage.
+// Already tagged transactions are skipped, so the analysis could be rerun safely.
+// Wrong options are rejected the same way as by SetAnalysisOptions.
+func (l *Ledger) Analyze(opts AnalysisOptions) (res AnalysisResult, err error) {
+	if err := opts.validate(); err != nil {
+		return res, err
+	}
+
+	pairs := make(map[[2]ID][]Transaction)
+	sources := make(map[ID][]Transaction)
+
+	trs := l.tr.List()
+	voided := voidedTransactions(trs)
+	for _, t := range trs {
+		if t.Deleted || t.IsInitial() || t.Voids != "" || voided[t.ID] {
+			continue
+		}
+		pairs[[2]ID{t.Source, t.Dest}] = append(pairs[[2]ID{t.Source, t.Dest}], t)
+		sources[t.Source] = append(sources[t.Source], t)
+	}
+
+	for _, trs := range pairs {
+		for _, t := range periodic(trs, opts) {
+			if l.tagItem(Periodic, t) {
+				res.Periodic = append(res.Periodic, t)
+			}
+		}
+	}
+
+	for _, trs := range sources {
+		for _, t := range overAverage(trs, opts) {
+			if l.tagItem(OverAverage, t) {
+				res.OverAverage = append(res.OverAverage, t)
+			}
+		}
+	}
+
+	slices.Sort(res.Periodic)
+	slices.Sort(res.OverAverage)
+	return res, nil
+}
+
+// Transactions reversed by the reversing entries among the given ones.
+func voidedTransactions(trs []Transaction) map[ID]bool {
+	voided := make(map[ID]bool)
+	for _, t := range trs {
+		if !t.Deleted && t.Voids != "" {
+			voided[t.Voids] = true
+		}
+	}
+	return voided
+}
+
+// Find recurring payments among transactions between the same accounts.
+func periodic(trs []Transaction, opts AnalysisOptions) (found []ID) {
+	if len(trs) < opts.MinOccurrences {
+		return
+	}
+
+	values := make([]float64, len(trs))
+	for i, t := range trs {
+		values[i] = t.Value.Float()
+	}
+	m := median(values)
+
+	var similar []Transaction
+	for _, t := range trs {
+		if math.Abs(t.Value.Float()-m) <= opts.AmountTolerance*m {
+			similar = append(similar, t)
+		}
+	}
+	if len(similar) < max(opts.MinOccurrences, 2) {
+		return
+	}
+	sortByTime(similar)
+
+	intervals := make([]float64, len(similar)-1)
+	for i := range intervals {
+		intervals[i] = float64(similar[i+1].Time.Sub(similar[i].Time))
+	}
+	mi := median(intervals)
+	if mi < float64(opts.MinInterval) || mi > float64(opts.MaxInterval) {
+		return
+	}
+
+	// the transaction is regular if it has a neighbour at regular interval:
+	regular := func(i int) bool { return math.Abs(intervals[i]-mi) <= opts.IntervalTolerance*mi }
+	for i, t := range similar {
+		if (i > 0 && regular(i-1)) || (i < len(intervals) && regular(i)) {
+			found = append(found, t.ID)
+		}
+	}
+	if len(found) < opts.MinOccurrences {
+		return nil
+	}
+	return
+}
+
+// Find transactions far above the rolling average of source account.
+func overAverage(trs []Transaction, opts AnalysisOptions) (found []ID) {
+	sortByTime(trs)
+
+	for i, t := range trs {
+		history := trs[max(0, i-opts.Window):i]
+		if len(history) < opts.MinHistory || len(history) == 0 {
+			continue
+		}
+
+		var sum float64
+		for _, h := range history {
+			sum += h.Value.Float()
+		}
+		if t.Value.Float() > opts.AverageFactor*sum/float64(len(history)) {
+			found = append(found, t.ID)
+		}
+	}
+	return
+}
+
+func median(values []float64) float64 {
+	if len(values) == 0 {
+		return 0
+	}
+	s := slices.Clone(values)
+	slices.Sort(s)
+	if n := len(s); n%2 == 0 {
+		return (s[n/2-1] + s[n/2]) / 2
+	}
+	return s[len(s)/2]
+}
+
+func sortByTime(trs []Transaction) {
+	slices.SortFunc(trs, compareTransactions)
+}
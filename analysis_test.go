@@ -0,0 +1,136 @@
+package miser
+
+import (
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestAnalyze(t *testing.T) {
+	t.Parallel()
+
+	// Create repositories:
+	ar := CreateAccountRegistry()
+	tr := CreateTransactionRegistry()
+	br := CreateBalanceRegistry()
+	cr := CreateCurrencyRegistry()
+	tg := CreateTagRegistry()
+	tm := CreateTagsMapRegistry()
+
+	// Create service:
+	l := CreateLedger(ar, br, tr, cr, tg, tm)
+
+	openedAt := time.Date(2024, time.January, 1, 8, 0, 0, 0, time.UTC)
+	bank, err := l.CreateAccount("Bank", Asset, "checking", "USD", openedAt, "10000")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	gym, err := l.CreateAccount("Gym", Expense, "membership", "USD", openedAt, "0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	shop, err := l.CreateAccount("Shop", Expense, "groceries", "USD", openedAt, "0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var gymFees []ID
+	for i, v := range []string{"30", "30", "31.50", "30"} {
+		transa, err := l.CreateTransaction(bank.ID, gym.ID, openedAt.AddDate(0, i, 4), v, "gym")
+		if err != nil {
+			t.Fatal(err)
+		}
+		gymFees = append(gymFees, transa.ID)
+	}
+
+	// irregular purchases, the last one is far above the average:
+	var purchases []ID
+	for i, v := range []string{"25", "40", "35", "500"} {
+		transa, err := l.CreateTransaction(bank.ID, shop.ID, openedAt.AddDate(0, 0, 3*i*i+1), v, "food")
+		if err != nil {
+			t.Fatal(err)
+		}
+		purchases = append(purchases, transa.ID)
+	}
+
+	res, err := l.Analyze(DefaultAnalysisOptions())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	slices.Sort(gymFees)
+	if !slices.Equal(res.Periodic, gymFees) {
+		t.Errorf("expected gym fees to be periodic: %v, got: %v", gymFees, res.Periodic)
+	}
+
+	// the gym fees are small in comparison with purchases and do not hit the average factor,
+	// the last purchase does:
+	if !slices.Contains(res.OverAverage, purchases[3]) {
+		t.Errorf("expected the last purchase to be over average, got: %v", res.OverAverage)
+	}
+	for _, id := range purchases[:3] {
+		if slices.Contains(res.OverAverage, id) {
+			t.Errorf("unexpected over average purchase: %s", id)
+		}
+	}
+
+	t.Run("rerun", func(t *testing.T) {
+		queued := len(tm.SyncQueued())
+
+		res, err := l.Analyze(DefaultAnalysisOptions())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(res.Periodic) != 0 || len(res.OverAverage) != 0 {
+			t.Errorf("expected nothing to be tagged again, got: %#v", res)
+		}
+		if n := len(tm.SyncQueued()); n != queued {
+			t.Errorf("expected %d tag maps, got: %d", queued, n)
+		}
+	})
+
+	t.Run("thresholds", func(t *testing.T) {
+		opts := DefaultAnalysisOptions()
+		opts.MinOccurrences = 5
+		opts.AverageFactor = 100
+
+		if _, err := l.CreateTransaction(bank.ID, shop.ID, openedAt.AddDate(0, 3, 0), "5000", "tv"); err != nil {
+			t.Fatal(err)
+		}
+		if res, err := l.Analyze(opts); err != nil || len(res.OverAverage) != 0 {
+			t.Errorf("expected nothing to be over average with factor 100, got: %v", res.OverAverage)
+		}
+	})
+
+	t.Run("options", func(t *testing.T) {
+		opts := DefaultAnalysisOptions()
+		opts.MinOccurrences = 2
+		opts.AmountTolerance = 0
+
+		cinema, err := l.CreateAccount("Cinema", Expense, "", "USD", openedAt, "0")
+		if err != nil {
+			t.Fatal(err)
+		}
+		for i, v := range []string{"10", "20"} { // the median is not equal to any of them
+			if _, err := l.CreateTransaction(bank.ID, cinema.ID, openedAt.AddDate(0, i, 1), v, "movie"); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if _, err := l.Analyze(opts); err != nil { // no similar transactions should not panic
+			t.Fatal(err)
+		}
+
+		opts.MinOccurrences = 0
+		if _, err := l.Analyze(opts); err == nil {
+			t.Error("expected error of wrong options of analysis")
+		}
+		if err := l.SetAnalysisOptions(opts); err == nil {
+			t.Error("expected error of wrong options")
+		}
+		if err := l.SetAnalysisOptions(DefaultAnalysisOptions()); err != nil {
+			t.Errorf("expected default options to be valid, got: %s", err)
+		}
+	})
+}
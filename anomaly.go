@@ -0,0 +1,194 @@
+package miser
+
+import (
+	"math"
+	"slices"
+	"sync"
+	"time"
+)
+
+// AnomalyOptions are thresholds of spending anomaly detection.
+type AnomalyOptions struct {
+	Threshold      float64 // z-score of value above which the spending is an outlier
+	MinSamples     int     // minimal number of learned spendings to judge
+	NewCounterpart bool    // whether spending to never seen counterpart is unexpected
+}
+
+func DefaultAnomalyOptions() AnomalyOptions {
+	return AnomalyOptions{Threshold: 3, MinSamples: 5, NewCounterpart: true}
+}
+
+// Running statistics of values (Welford's algorithm).
+type spendStats struct {
+	n        int
+	mean, m2 float64
+}
+
+func (s *spendStats) add(v float64) {
+	s.n++
+	d := v - s.mean
+	s.mean += d / float64(s.n)
+	s.m2 += d * (v - s.mean)
+}
+
+// Standard score of value, the deviation is not less than 5% of mean
+// (the spendings of the same value would make every other one an outlier).
+func (s *spendStats) score(v float64) float64 {
+	sd := math.Sqrt(s.m2 / float64(s.n))
+	sd = max(sd, 0.05*math.Abs(s.mean))
+	if sd == 0 {
+		return 0
+	}
+	return (v - s.mean) / sd
+}
+
+// AnomalyDetector learns typical spending per expense account and its weekday
+// and month patterns, then judges whether a new spending is unexpected.
+type AnomalyDetector struct {
+	opts     AnomalyOptions
+	accounts map[ID]*spendStats
+	weekdays map[ID]*[7]spendStats
+	months   map[ID]*[12]spendStats
+	pairs    map[[2]ID]bool // seen counterparts: source and expense accounts
+	sources  map[ID]int     // number of learned spendings of source account
+	stale    bool           // posted transactions were changed after the spendings were learned
+
+	sync.Mutex
+}
+
+func CreateAnomalyDetector(opts AnomalyOptions) *AnomalyDetector {
+	d := &AnomalyDetector{opts: opts}
+	d.clear()
+	return d
+}
+
+func (d *AnomalyDetector) clear() {
+	d.accounts = make(map[ID]*spendStats)
+	d.weekdays = make(map[ID]*[7]spendStats)
+	d.months = make(map[ID]*[12]spendStats)
+	d.pairs = make(map[[2]ID]bool)
+	d.sources = make(map[ID]int)
+	d.stale = false
+}
+
+// Forget all learned spendings, the options are kept.
+func (d *AnomalyDetector) Reset() {
+	d.Lock()
+	defer d.Unlock()
+	d.clear()
+}
+
+// Mark the learned spendings as outdated, e.g. after a change of posted transaction.
+func (d *AnomalyDetector) invalidate() {
+	d.Lock()
+	defer d.Unlock()
+	d.stale = true
+}
+
+func (d *AnomalyDetector) isStale() bool {
+	d.Lock()
+	defer d.Unlock()
+	return d.stale
+}
+
+// Learn the spending: transaction to expense account.
+func (d *AnomalyDetector) Learn(t Transaction) {
+	d.Lock()
+	defer d.Unlock()
+
+	v := t.Value.Float()
+
+	if d.accounts[t.Dest] == nil {
+		d.accounts[t.Dest] = &spendStats{}
+		d.weekdays[t.Dest] = &[7]spendStats{}
+		d.months[t.Dest] = &[12]spendStats{}
+	}
+	d.accounts[t.Dest].add(v)
+	d.weekdays[t.Dest][t.Time.Weekday()].add(v)
+	d.months[t.Dest][t.Time.Month()-1].add(v)
+
+	d.pairs[[2]ID{t.Source, t.Dest}] = true
+	d.sources[t.Source]++
+}
+
+// Check the spending before learning it: whether it is a statistical outlier
+// among the spendings of its expense account or goes to never seen counterpart.
+// The most specific pattern with enough samples is used: weekday, month or whole account.
+func (d *AnomalyDetector) Check(t Transaction) bool {
+	d.Lock()
+	defer d.Unlock()
+
+	if d.opts.NewCounterpart && d.sources[t.Source] >= d.opts.MinSamples && !d.pairs[[2]ID{t.Source, t.Dest}] {
+		return true
+	}
+
+	acc := d.accounts[t.Dest]
+	if acc == nil {
+		return false
+	}
+
+	for _, s := range []*spendStats{&d.weekdays[t.Dest][t.Time.Weekday()], &d.months[t.Dest][t.Time.Month()-1], acc} {
+		if s.n >= d.opts.MinSamples {
+			return s.score(t.Value.Float()) > d.opts.Threshold
+		}
+	}
+	return false
+}
+
+// Set options of spending anomaly detection, the learned spendings are kept.
+func (l *Ledger) SetAnomalyOptions(opts AnomalyOptions) {
+	l.ad.Lock()
+	defer l.ad.Unlock()
+	l.ad.opts = opts
+}
+
+// Learn spending (and check it before if needed), unexpected spending is tagged as Unexpected.
+func (l *Ledger) inspectSpending(t *Transaction, check bool) {
+	if acc := l.ar.Get(t.Dest); acc == nil || acc.Type != Expense || t.IsInitial() {
+		return
+	}
+	if check && l.ad.Check(*t) {
+		l.tagItem(Unexpected, t.ID)
+	}
+	l.ad.Learn(*t)
+}
+
+// Learn all spendings of ledger in chronological order from scratch (e.g. after the load),
+// voided transactions and reversing entries are skipped.
+func (l *Ledger) LearnSpendings() {
+	l.ad.Reset()
+
+	trs := l.tr.List()
+	voided := voidedTransactions(trs)
+	sortByTime(trs)
+	for _, t := range trs {
+		if !t.Deleted && t.Voids == "" && !voided[t.ID] {
+			l.inspectSpending(&t, false)
+		}
+	}
+}
+
+// Relearn spendings if posted transactions were changed after they were learned,
+// the changes only mark them as outdated, so a series of changes is relearned once.
+func (l *Ledger) refreshSpendings() {
+	if l.ad.isStale() {
+		l.LearnSpendings()
+	}
+}
+
+// Unexpected spendings in given period [from, to), sorted by time, use it for review.
+func (l *Ledger) Unexpected(from, to time.Time) (trs []Transaction) {
+	tag := l.tg.GetByName(Unexpected)
+	if tag == nil {
+		return
+	}
+
+	for _, id := range l.tm.Items(tag.ID) {
+		t := l.tr.Get(id)
+		if t != nil && !t.Deleted && l.tm.Has(tag.ID, id) && !t.Time.Before(from) && t.Time.Before(to) {
+			trs = append(trs, *t)
+		}
+	}
+	sortByTime(trs)
+	return slices.Clip(trs)
+}
@@ -0,0 +1,130 @@
+package miser
+
+import (
+	"testing"
+	"time"
+)
+
+func TestUnexpected(t *testing.T) {
+	t.Parallel()
+
+	// Create repositories:
+	ar := CreateAccountRegistry()
+	tr := CreateTransactionRegistry()
+	br := CreateBalanceRegistry()
+	cr := CreateCurrencyRegistry()
+	tg := CreateTagRegistry()
+	tm := CreateTagsMapRegistry()
+
+	// Create service:
+	l := CreateLedger(ar, br, tr, cr, tg, tm)
+
+	openedAt := time.Date(2024, time.January, 1, 9, 0, 0, 0, time.UTC)
+	bank, err := l.CreateAccount("Bank", Asset, "checking", "USD", openedAt, "10000")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	shop, err := l.CreateAccount("Shop", Expense, "groceries", "USD", openedAt, "0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	casino, err := l.CreateAccount("Casino", Expense, "", "USD", openedAt, "0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	create := func(dst ID, day int, v string) *Transaction {
+		transa, err := l.CreateTransaction(bank.ID, dst, openedAt.AddDate(0, 0, day), v, "")
+		if err != nil {
+			t.Fatal(err)
+		}
+		return transa
+	}
+
+	var usual []*Transaction
+	for i, v := range []string{"50", "55", "45", "52", "48", "51", "49", "53"} {
+		usual = append(usual, create(shop.ID, 7*(i+1), v))
+	}
+
+	outlier := create(shop.ID, 63, "500")
+	regular := create(shop.ID, 70, "54")
+	stranger := create(casino.ID, 71, "20")
+
+	unexpected := l.Unexpected(openedAt, openedAt.AddDate(1, 0, 0))
+	if len(unexpected) != 2 || unexpected[0].ID != outlier.ID || unexpected[1].ID != stranger.ID {
+		t.Errorf("expected outlier and new counterpart to be unexpected, got: %v", unexpected)
+	}
+
+	for _, transa := range append(usual, regular) {
+		if tagID := tg.GetByName(Unexpected).ID; tm.Has(tagID, transa.ID) {
+			t.Errorf("usual spending is tagged as unexpected: %s", transa.Value)
+		}
+	}
+
+	if n := len(l.Unexpected(openedAt, outlier.Time)); n != 0 {
+		t.Errorf("expected no unexpected spendings before the outlier, got: %d", n)
+	}
+
+	t.Run("relearn", func(t *testing.T) {
+		l.LearnSpendings()
+		if l.ad.Check(*stranger) {
+			t.Error("expected known counterpart after relearning")
+		}
+		if l.ad.Check(*regular) {
+			t.Error("expected regular spending to be usual after relearning")
+		}
+
+		huge := *outlier
+		huge.Value = MustParseAmount("1000")
+		if !l.ad.Check(huge) {
+			t.Error("expected huge spending to be unexpected after relearning")
+		}
+	})
+
+	t.Run("changes", func(t *testing.T) {
+		probe := *regular
+		probe.Value = MustParseAmount("100")
+		if l.ad.Check(probe) {
+			t.Error("expected spending of 100 to be usual next to the outlier")
+		}
+
+		ad := l.ad
+		if _, err := l.UpdateTransaction(outlier.ID, bank.ID, shop.ID, outlier.Time, "50", ""); err != nil {
+			t.Fatal(err)
+		}
+		if n := len(l.Unexpected(openedAt, openedAt.AddDate(1, 0, 0))); n != 1 {
+			t.Errorf("expected the updated outlier to be untagged, got: %d unexpected", n)
+		}
+		if err := l.DeleteTransaction(stranger.ID); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := l.VoidTransaction(usual[0].ID, openedAt.AddDate(0, 3, 0)); err != nil {
+			t.Fatal(err)
+		}
+
+		// the changes are relearned once, before the next spending:
+		if !l.ad.isStale() || l.ad.accounts[shop.ID].n != 10 {
+			t.Error("expected spendings to be relearned lazily")
+		}
+		l.refreshSpendings()
+		if l.ad != ad || l.ad.isStale() {
+			t.Error("expected the same detector to be relearned")
+		}
+		if n := l.ad.accounts[shop.ID].n; n != 9 {
+			t.Errorf("expected 9 learned spendings after the changes, got: %d", n)
+		}
+		if !l.ad.Check(*stranger) {
+			t.Error("expected new counterpart after the deletion of its only spending")
+		}
+
+		created, err := l.CreateTransaction(bank.ID, shop.ID, probe.Time.AddDate(0, 0, 7), "100", "")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !tm.Has(tg.GetByName(Unexpected).ID, created.ID) {
+			t.Error("expected spending of 100 to be unexpected after the update of outlier")
+		}
+	})
+}
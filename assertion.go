@@ -0,0 +1,232 @@
+package miser
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+)
+
+// Assertion is an expected balance of account at the end of the day, e.g.
+// "on 2024-05-31 account SMBC must equal ¥1,234,567", it catches entry mistakes.
+type Assertion struct {
+	ID, Account ID
+	Date        time.Time // the balance is checked at the end of this day
+	Value       Amount
+	Deleted     bool
+}
+
+// AssertionError is returned by changes of ledger which would break the assertion.
+type AssertionError struct {
+	Assertion Assertion
+	Actual    Amount // balance of account at the end of the day after the change
+}
+
+func (e *AssertionError) Error() string {
+	return fmt.Sprintf("balance assertion %s failed: balance of account %s at %s should be %s, got %s",
+		e.Assertion.ID, e.Assertion.Account, e.Assertion.Date.Format(time.DateOnly), e.Assertion.Value, e.Actual)
+}
+
+type AssertionRegistry struct {
+	items  map[ID]Assertion
+	queued map[ID]Assertion
+
+	sync.RWMutex
+}
+
+func (as *AssertionRegistry) List() map[ID]Assertion {
+	as.RLock()
+	defer as.RUnlock()
+	return as.items
+}
+
+func (as *AssertionRegistry) Get(asID ID) *Assertion {
+	as.RLock()
+	defer as.RUnlock()
+	a, ok := as.items[asID]
+	if ok {
+		return &a
+	}
+	return nil
+}
+
+// Assertions of account sorted by date.
+func (as *AssertionRegistry) ForAccount(accID ID) (assertions []Assertion) {
+	as.RLock()
+	defer as.RUnlock()
+
+	for _, a := range as.items {
+		if !a.Deleted && a.Account == accID {
+			assertions = append(assertions, a)
+		}
+	}
+	slices.SortFunc(assertions, compareAssertions)
+	return
+}
+
+func compareAssertions(a, b Assertion) int {
+	if c := a.Date.Compare(b.Date); c != 0 {
+		return c
+	}
+	if c := cmp.Compare(a.Account, b.Account); c != 0 {
+		return c
+	}
+	return cmp.Compare(a.ID, b.ID)
+}
+
+func (as *AssertionRegistry) Add(a Assertion) int {
+	as.Lock()
+	defer as.Unlock()
+	as.items[a.ID] = a
+	return 1
+}
+
+func (as *AssertionRegistry) AddQueued(a Assertion) {
+	as.Lock()
+	defer as.Unlock()
+	as.queued[a.ID] = a
+}
+
+func (as *AssertionRegistry) SyncQueued() (changes []Assertion) {
+	as.RLock()
+	defer as.RUnlock()
+	for _, a := range as.queued {
+		changes = append(changes, a)
+	}
+	return
+}
+
+func CreateAssertionRegistry() *AssertionRegistry {
+	return &AssertionRegistry{
+		items:  make(map[ID]Assertion),
+		queued: make(map[ID]Assertion),
+	}
+}
+
+func (as *AssertionRegistry) Load() (int, error) { return Load(as, ASSERTIONS_FILE) }
+func (as *AssertionRegistry) Save() (int, error) { return Save(as, ASSERTIONS_FILE) }
+
+// Create balance assertion of account at the end of given day, it should hold already.
+func (l *Ledger) CreateAssertion(accID ID, date time.Time, v string) (*Assertion, error) {
+	acc := l.ar.Get(accID)
+	if acc == nil {
+		return nil, errors.New("account not found")
+	}
+
+	value, err := l.parseAmount(acc, v)
+	if err != nil {
+		return nil, err
+	}
+
+	a := Assertion{ID: CreateID(), Account: accID, Date: date, Value: value}
+	actual, err := l.AccountBalanceAt(accID, nextDay(date), false)
+	if err != nil {
+		return nil, err
+	}
+	if !actual.Equal(value) {
+		return nil, &AssertionError{Assertion: a, Actual: actual}
+	}
+
+	l.as.Add(a)
+	l.as.AddQueued(a)
+	return &a, nil
+}
+
+// Delete balance assertion.
+func (l *Ledger) DeleteAssertion(asID ID) error {
+	a := l.as.Get(asID)
+	if a == nil || a.Deleted {
+		return errors.New("assertion not found")
+	}
+	a.Deleted = true
+	l.as.Add(*a)
+	l.as.AddQueued(*a)
+	return nil
+}
+
+// AssertionResult is a result of check of balance assertion.
+type AssertionResult struct {
+	Assertion
+	Actual Amount
+}
+
+func (r AssertionResult) Ok() bool { return r.Actual.Equal(r.Value) }
+
+// Check all balance assertions, the results are sorted by date.
+func (l *Ledger) CheckAssertions() (results []AssertionResult, err error) {
+	for _, a := range l.as.List() {
+		if a.Deleted {
+			continue
+		}
+		actual, err := l.AccountBalanceAt(a.Account, nextDay(a.Date), false)
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, AssertionResult{Assertion: a, Actual: actual})
+	}
+	slices.SortFunc(results, func(a, b AssertionResult) int { return compareAssertions(a.Assertion, b.Assertion) })
+	return
+}
+
+// Change of balance of account since given time.
+type balanceChange struct {
+	account ID
+	at      time.Time
+	delta   Amount
+}
+
+// Changes of balances of accounts made by transaction (or its removal).
+func (l *Ledger) transactionChanges(t *Transaction, removal bool) (changes []balanceChange) {
+	for _, p := range []struct {
+		accID    ID
+		operType int
+	}{{t.Source, Credit}, {t.Dest, Debit}} {
+		acc := l.ar.Get(p.accID)
+		if acc == nil {
+			continue
+		}
+		delta := effect(string(acc.Type), p.operType, t.Value)
+		if removal {
+			delta = delta.Neg()
+		}
+		changes = append(changes, balanceChange{account: p.accID, at: t.Time, delta: delta})
+	}
+	return
+}
+
+// Check assertions affected by the changes of balances before making them.
+func (l *Ledger) checkAssertions(changes ...balanceChange) error {
+	for _, accID := range uniqueIDs(changedAccounts(changes)...) {
+		for _, a := range l.as.ForAccount(accID) {
+			end := nextDay(a.Date)
+
+			var delta Amount
+			for _, c := range changes {
+				if c.account == accID && c.at.Before(end) {
+					delta = delta.Add(c.delta)
+				}
+			}
+			if delta.IsZero() {
+				continue // not affected, e.g. the transaction is moved within the same day
+			}
+
+			actual, err := l.AccountBalanceAt(accID, end, false)
+			if err != nil {
+				return err
+			}
+			if actual = actual.Add(delta); !actual.Equal(a.Value) {
+				return &AssertionError{Assertion: a, Actual: actual}
+			}
+		}
+	}
+	return nil
+}
+
+func changedAccounts(changes []balanceChange) (ids []ID) {
+	for _, c := range changes {
+		ids = append(ids, c.account)
+	}
+	return
+}
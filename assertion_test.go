@@ -0,0 +1,100 @@
+package miser
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestAssertions(t *testing.T) {
+	t.Parallel()
+
+	// Create repositories:
+	ar := CreateAccountRegistry()
+	tr := CreateTransactionRegistry()
+	br := CreateBalanceRegistry()
+	cr := CreateCurrencyRegistry()
+	tg := CreateTagRegistry()
+	tm := CreateTagsMapRegistry()
+
+	// Create service:
+	l := CreateLedger(ar, br, tr, cr, tg, tm)
+
+	openedAt := time.Date(2024, time.May, 1, 9, 0, 0, 0, time.UTC)
+	smbc, err := l.CreateAccount("SMBC", Asset, "salary account", "JPY", openedAt, "1234567")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	aeon, err := l.CreateAccount("AEON", Expense, "supermarket", "JPY", openedAt, "0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	endOfMay := time.Date(2024, time.May, 31, 0, 0, 0, 0, time.UTC)
+	if _, err := l.CreateAssertion(smbc.ID, endOfMay, "1000000"); err == nil {
+		t.Error("error expected for assertion which does not hold, nil found")
+	}
+
+	a, err := l.CreateAssertion(smbc.ID, endOfMay, "1234567")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var aerr *AssertionError
+
+	// back-dated insert breaks the assertion:
+	if _, err := l.CreateTransaction(smbc.ID, aeon.ID, endOfMay.Add(20*time.Hour), "5000", ""); !errors.As(err, &aerr) {
+		t.Fatalf("expected assertion error, got: %v", err)
+	}
+	if aerr.Assertion.ID != a.ID || aerr.Actual.String() != "1229567" {
+		t.Errorf("unexpected assertion error: %s", aerr)
+	}
+	if n := len(l.tr.Since(smbc.ID, endOfMay)); n != 0 {
+		t.Errorf("expected no transaction created, got: %d", n)
+	}
+
+	// transactions after the day are not affected:
+	june, err := l.CreateTransaction(smbc.ID, aeon.ID, endOfMay.AddDate(0, 0, 1), "5000", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// but moving them to the day is:
+	if _, err := l.UpdateTransaction(june.ID, smbc.ID, aeon.ID, endOfMay.Add(-time.Hour), "5000", ""); !errors.As(err, &aerr) {
+		t.Errorf("expected assertion error for edit, got: %v", err)
+	}
+
+	// deletion of transaction after the day is not checked:
+	may, err := l.CreateTransaction(smbc.ID, aeon.ID, endOfMay.AddDate(0, 0, 2), "100", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := l.DeleteTransaction(may.ID); err != nil {
+		t.Errorf("unexpected error: %s", err)
+	}
+
+	// while deletion of transaction of the day breaks assertion:
+	june2, err := l.CreateAssertion(aeon.ID, endOfMay.AddDate(0, 0, 1), "5000")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := l.DeleteTransaction(june.ID); !errors.As(err, &aerr) || aerr.Assertion.ID != june2.ID {
+		t.Errorf("expected assertion error for deletion, got: %v", err)
+	}
+
+	if err := l.DeleteAssertion(june2.ID); err != nil {
+		t.Fatal(err)
+	}
+	if err := l.DeleteTransaction(june.ID); err != nil {
+		t.Errorf("unexpected error after deletion of assertion: %s", err)
+	}
+
+	results, err := l.CheckAssertions()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != 1 || !results[0].Ok() || results[0].ID != a.ID {
+		t.Errorf("unexpected results of assertions check: %v", results)
+	}
+}
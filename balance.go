@@ -15,11 +15,12 @@ const (
 // the last version will be used (see Add method of BalanceRegistry).
 type Balance struct {
 	Account, Transaction ID // in fact the id of balance item is transaction id
-	Value                int64
+	Value                Amount
+	Deleted              bool // the transaction does not belong to the account anymore
 }
 
 func (b Balance) ID() string      { return fmt.Sprintf("%s-%s", b.Account, b.Transaction) }
-func (b Balance) Amount() float64 { return float64(b.Value) / Million }
+func (b Balance) Amount() float64 { return b.Value.Float() }
 
 type BalanceRegistry struct {
 	items  map[string]Balance // order matters, items loaded from disk, last their versions
@@ -46,7 +47,7 @@ func (br *BalanceRegistry) TransactionBalance(accID, trID ID) *Balance {
 
 	key := fmt.Sprintf("%s-%s", accID, trID)
 	b, ok := br.items[key]
-	if ok {
+	if ok && !b.Deleted {
 		return &b
 	}
 	return nil
@@ -83,25 +84,3 @@ func CreateBalanceRegistry() *BalanceRegistry {
 
 func (br *BalanceRegistry) Load() (int, error) { return Load(br, BALANCE_FILE) }
 func (br *BalanceRegistry) Save() (int, error) { return Save(br, BALANCE_FILE) }
-
-// The rearranged accounting equation:
-// Assets + Expenses = Liabilities + Equity + Income
-// func CheckBalance() int64 {
-// 	var as, li, eq, in, ex int64
-// 	for accID, bl := range Balances.Items {
-// 		acc, _ := Accounts.Get(accID)
-// 		switch acc.Type {
-// 		case Asset:
-// 			as += bl.Value
-// 		case Liability:
-// 			li += bl.Value
-// 		case Equity:
-// 			eq += bl.Value
-// 		case Income:
-// 			in += bl.Value
-// 		case Expense:
-// 			ex += bl.Value
-// 		}
-// 	}
-// 	return as + ex - li - eq - in
-// }
@@ -21,7 +21,7 @@ func TestBalanceInit(t *testing.T) {
 	l := CreateLedger(ar, br, tr, cr, tg, tm)
 
 	t.Run("zero", func(t *testing.T) {
-		acc, err := l.CreateAccount("Deposit", Asset, "deposit account", "USD", time.Now(), 0.00)
+		acc, err := l.CreateAccount("Deposit", Asset, "deposit account", "USD", time.Now(), "0")
 		if err != nil {
 			t.Fatal(err)
 		}
@@ -31,13 +31,13 @@ func TestBalanceInit(t *testing.T) {
 			t.Fatal("balance was not created during account creation")
 		}
 
-		if b.Value != 0 {
-			t.Errorf("expected 0, %d found", b.Value)
+		if !b.Value.IsZero() {
+			t.Errorf("expected 0, %s found", b.Value)
 		}
 	})
 
 	t.Run("float", func(t *testing.T) {
-		acc, err := l.CreateAccount("Deposit", Asset, "deposit account", "USD", time.Now(), 123.78)
+		acc, err := l.CreateAccount("Deposit", Asset, "deposit account", "USD", time.Now(), "123.78")
 		if err != nil {
 			t.Fatal(err)
 		}
@@ -63,12 +63,12 @@ func TestBalanceChange(t *testing.T) {
 	l := CreateLedger(ar, br, tr, cr, tg, tm)
 
 	t.Run("Expense", func(t *testing.T) {
-		cash, err := l.CreateAccount("Cash", Asset, "wallet", "USD", time.Now(), 1555.12)
+		cash, err := l.CreateAccount("Cash", Asset, "wallet", "USD", time.Now(), "1555.12")
 		if err != nil {
 			t.Fatal(err)
 		}
 
-		market, err := l.CreateAccount("Market", Expense, "holiday market", "USD", time.Now(), 343.11)
+		market, err := l.CreateAccount("Market", Expense, "holiday market", "USD", time.Now(), "343.11")
 		if err != nil {
 			t.Fatal(err)
 		}
@@ -77,7 +77,7 @@ func TestBalanceChange(t *testing.T) {
 			t.Errorf("expected 344.64, got: %.2f", openMarketBalance)
 		}
 
-		_, err = l.CreateTransaction(cash.ID, market.ID, time.Now(), 1.53, "1kg carrot")
+		_, err = l.CreateTransaction(cash.ID, market.ID, time.Now(), "1.53", "1kg carrot")
 		if err != nil {
 			t.Fatal(err)
 		}
@@ -95,11 +95,11 @@ func TestBalanceChange(t *testing.T) {
 	t.Run("Earnings", func(t *testing.T) {
 		var buyers []Account
 		for i := 0; i < 5; i++ {
-			acc, _ := l.CreateAccount("Cash", Asset, "wallet", "USD", time.Now(), 1555.12)
+			acc, _ := l.CreateAccount("Cash", Asset, "wallet", "USD", time.Now(), "1555.12")
 			buyers = append(buyers, *acc)
 		}
 
-		market, err := l.CreateAccount("Market", Expense, "holiday market", "USD", time.Now(), 343.11)
+		market, err := l.CreateAccount("Market", Expense, "holiday market", "USD", time.Now(), "343.11")
 		if err != nil {
 			t.Fatal(err)
 		}
@@ -107,13 +107,13 @@ func TestBalanceChange(t *testing.T) {
 		openMarketBalance := l.AccountBalance(market.ID)
 		t.Logf("open market balance: %#v", openMarketBalance)
 		for i := 0; i < 5; i++ { // buy 5 kg of carrot
-			_, _ = l.CreateTransaction(buyers[i].ID, market.ID, time.Now(), 1.53, "1kg carrot")
+			_, _ = l.CreateTransaction(buyers[i].ID, market.ID, time.Now(), "1.53", "1kg carrot")
 		}
 
 		closeMarketBalance := l.AccountBalance(market.ID)
 		t.Logf("close market balance: %#v", closeMarketBalance)
 
-		earnings := float64(closeMarketBalance.Value-openMarketBalance.Value) / Million
+		earnings := closeMarketBalance.Value.Sub(openMarketBalance.Value).Float()
 		if earnings != 7.65 {
 			t.Logf("%#v", earnings)
 			t.Errorf("expected earnings for sold 5kg of carrot: %+.2f, got: %+.2f", 5*1.53, earnings)
@@ -124,11 +124,11 @@ func TestBalanceChange(t *testing.T) {
 		var pubs []Account
 
 		for i := 0; i < 5; i++ {
-			acc, _ := l.CreateAccount("Shop", Asset, "LC Waikiki", "USD", time.Now(), 15.12)
+			acc, _ := l.CreateAccount("Shop", Asset, "LC Waikiki", "USD", time.Now(), "15.12")
 			pubs = append(pubs, *acc)
 		}
 
-		cash, err := l.CreateAccount("Cash", Expense, "wallet", "USD", time.Now(), 343.11)
+		cash, err := l.CreateAccount("Cash", Expense, "wallet", "USD", time.Now(), "343.11")
 		if err != nil {
 			t.Fatal(err)
 		}
@@ -136,12 +136,12 @@ func TestBalanceChange(t *testing.T) {
 		openPartyBalance := l.AccountBalance(cash.ID)
 
 		for i := 0; i < 5; i++ { // buy 5 kg of carrot
-			_, _ = l.CreateTransaction(cash.ID, pubs[i].ID, time.Now(), 1.53, "1 bear")
+			_, _ = l.CreateTransaction(cash.ID, pubs[i].ID, time.Now(), "1.53", "1 bear")
 		}
 
 		closePartyBalance := l.AccountBalance(cash.ID)
 
-		spendings := float64(closePartyBalance.Value-openPartyBalance.Value) / Million
+		spendings := closePartyBalance.Value.Sub(openPartyBalance.Value).Float()
 		if spendings != -7.65 {
 			t.Errorf("expected spendings for 5 bears: %+.2f, got: %+.2f", -7.65, spendings)
 		}
@@ -158,16 +158,14 @@ func TestBalanceList(t *testing.T) {
 	aid := CreateID()
 	tid := CreateID()
 
-	val := func(v float64) int64 { return int64(v * Million) }
-
 	// 3 redacts of the same balance:
-	br.Add(Balance{Account: aid, Transaction: tid, Value: val(1.11)})
-	br.Add(Balance{Account: aid, Transaction: tid, Value: val(1.12)})
-	br.Add(Balance{Account: aid, Transaction: tid, Value: val(3.15)})
+	br.Add(Balance{Account: aid, Transaction: tid, Value: MustParseAmount("1.11")})
+	br.Add(Balance{Account: aid, Transaction: tid, Value: MustParseAmount("1.12")})
+	br.Add(Balance{Account: aid, Transaction: tid, Value: MustParseAmount("3.15")})
 
 	tid2 := CreateID()
 
-	br.Add(Balance{Account: aid, Transaction: tid2, Value: val(1.76)})
+	br.Add(Balance{Account: aid, Transaction: tid2, Value: MustParseAmount("1.76")})
 
 	// count how many balances of transactions exist:
 	tBalances := make(map[ID]int)
@@ -201,18 +199,18 @@ func TestBalanceUpdate(t *testing.T) {
 
 	t.Run("Linear", func(t *testing.T) {
 		openedAt := time.Date(2024, time.January, 1, 8, 30, 0, 0, time.UTC)
-		wallet, err := l.CreateAccount("Cash", Asset, "wallet", "USD", openedAt, 1555.12)
+		wallet, err := l.CreateAccount("Cash", Asset, "wallet", "USD", openedAt, "1555.12")
 		if err != nil {
 			t.Fatal(err)
 		}
 
-		bazaar, err := l.CreateAccount("Bazaar", Expense, "sunday bazaar", "USD", openedAt, 0.50)
+		bazaar, err := l.CreateAccount("Bazaar", Expense, "sunday bazaar", "USD", openedAt, "0.50")
 		if err != nil {
 			t.Fatal(err)
 		}
 
 		shopingTime := openedAt.Add(2 * time.Hour)
-		if _, err := l.CreateTransaction(wallet.ID, bazaar.ID, shopingTime, 5.35, "oranges"); err != nil {
+		if _, err := l.CreateTransaction(wallet.ID, bazaar.ID, shopingTime, "5.35", "oranges"); err != nil {
 			t.Fatal(err)
 		}
 
@@ -225,7 +223,7 @@ func TestBalanceUpdate(t *testing.T) {
 		}
 
 		shopingTime2 := shopingTime.Add(30 * time.Minute)
-		if _, err := l.CreateTransaction(wallet.ID, bazaar.ID, shopingTime2, 2.13, "carrot"); err != nil {
+		if _, err := l.CreateTransaction(wallet.ID, bazaar.ID, shopingTime2, "2.13", "carrot"); err != nil {
 			t.Fatal(err)
 		}
 
@@ -240,19 +238,19 @@ func TestBalanceUpdate(t *testing.T) {
 
 	t.Run("Intermediate", func(t *testing.T) {
 		openedAt := time.Date(2024, time.October, 1, 15, 30, 0, 0, time.UTC)
-		wallet, err := l.CreateAccount("Cash", Asset, "wallet", "USD", openedAt, 200.37)
+		wallet, err := l.CreateAccount("Cash", Asset, "wallet", "USD", openedAt, "200.37")
 		if err != nil {
 			t.Fatal(err)
 		}
 
-		bazaar, err := l.CreateAccount("Bazaar", Expense, "sunday bazaar", "USD", openedAt, 0.50)
+		bazaar, err := l.CreateAccount("Bazaar", Expense, "sunday bazaar", "USD", openedAt, "0.50")
 		if err != nil {
 			t.Fatal(err)
 		}
 
 		// fist transaction at 20 Oct 15:30:
 		dt1 := time.Date(2024, time.October, 20, 15, 30, 0, 0, time.UTC)
-		tr1, err := l.CreateTransaction(wallet.ID, bazaar.ID, dt1, 2.13, "carrot")
+		tr1, err := l.CreateTransaction(wallet.ID, bazaar.ID, dt1, "2.13", "carrot")
 		if err != nil {
 			t.Fatal(err)
 		}
@@ -272,7 +270,7 @@ func TestBalanceUpdate(t *testing.T) {
 
 		// second transaction at 20 Oct 22:30:
 		dt2 := time.Date(2024, time.October, 20, 22, 30, 0, 0, time.UTC)
-		tr2, err := l.CreateTransaction(wallet.ID, bazaar.ID, dt2, 5.17, "oranges")
+		tr2, err := l.CreateTransaction(wallet.ID, bazaar.ID, dt2, "5.17", "oranges")
 		if err != nil {
 			t.Fatal(err)
 		}
@@ -292,7 +290,7 @@ func TestBalanceUpdate(t *testing.T) {
 		// third transaction, add forgotten expense, cheese after end of workday,
 		// between first and second transactions:
 		dt3 := time.Date(2024, time.October, 20, 17, 30, 0, 0, time.UTC)
-		tr3, err := l.CreateTransaction(wallet.ID, bazaar.ID, dt3, 150, "cheese")
+		tr3, err := l.CreateTransaction(wallet.ID, bazaar.ID, dt3, "150", "cheese")
 		if err != nil {
 			t.Fatal(err)
 		}
@@ -350,3 +348,111 @@ func TestBalanceUpdate(t *testing.T) {
 
 	})
 }
+
+func TestBalancePrecision(t *testing.T) {
+	t.Parallel()
+
+	// Create repositories:
+	ar := CreateAccountRegistry()
+	tr := CreateTransactionRegistry()
+	br := CreateBalanceRegistry()
+	cr := CreateCurrencyRegistry()
+	tg := CreateTagRegistry()
+	tm := CreateTagsMapRegistry()
+
+	// Create service:
+	l := CreateLedger(ar, br, tr, cr, tg, tm)
+
+	openedAt := time.Date(2024, time.January, 1, 8, 30, 0, 0, time.UTC)
+	wallet, err := l.CreateAccount("Wallet", Asset, "hot wallet", "ETH", openedAt, "100000000.000000000000000001")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	gas, err := l.CreateAccount("Gas", Expense, "network fees", "ETH", openedAt, "0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := l.CreateTransaction(wallet.ID, gas.ID, openedAt.Add(time.Hour), "0.000000000000000001", "1 wei"); err != nil {
+		t.Fatal(err)
+	}
+
+	if b := l.AccountBalance(wallet.ID); b.Value.String() != "100000000" {
+		t.Errorf("expected 100000000 ETH in wallet, found: %s", b.Value)
+	}
+
+	if b := l.AccountBalance(gas.ID); b.Value.String() != "0.000000000000000001" {
+		t.Errorf("expected 1 wei of gas, found: %s", b.Value)
+	}
+}
+
+func TestAccountBalanceAt(t *testing.T) {
+	t.Parallel()
+
+	for _, mode := range []int{JournalBalances, DerivedBalances} {
+		// Create service:
+		l := CreateLedger(CreateAccountRegistry(), CreateBalanceRegistry(), CreateTransactionRegistry(),
+			CreateCurrencyRegistry(), CreateTagRegistry(), CreateTagsMapRegistry())
+		if err := l.SetBalanceMode(mode); err != nil {
+			t.Fatal(err)
+		}
+
+		openedAt := time.Date(2024, time.January, 1, 9, 0, 0, 0, time.UTC)
+		wallet, err := l.CreateAccount("Cash", Asset, "wallet", "USD", openedAt, "500")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		bazaar, err := l.CreateAccount("Bazaar", Expense, "sunday bazaar", "USD", openedAt, "0")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		noon := time.Date(2024, time.January, 5, 12, 0, 0, 0, time.UTC)
+		for _, d := range []struct {
+			t time.Time
+			v string
+		}{{noon, "100"}, {noon, "50"}, {noon.AddDate(0, 0, 5), "20"}} {
+			if _, err := l.CreateTransaction(wallet.ID, bazaar.ID, d.t, d.v, ""); err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		tests := []struct {
+			t         time.Time
+			inclusive bool
+			want      string
+		}{
+			{openedAt.AddDate(0, 0, -1), true, "0"},
+			{openedAt, false, "0"},
+			{openedAt, true, "500"},
+			{noon, false, "500"},
+			{noon, true, "350"},
+			{noon.AddDate(0, 0, 2), false, "350"},
+			{noon.AddDate(1, 0, 0), false, "330"},
+		}
+
+		for _, tc := range tests {
+			v, err := l.AccountBalanceAt(wallet.ID, tc.t, tc.inclusive)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if v.String() != tc.want {
+				t.Errorf("mode %d: expected %s at %s (inclusive: %t), got: %s", mode, tc.want, tc.t, tc.inclusive, v)
+			}
+		}
+
+		balances, err := l.AccountBalancesAt([]ID{wallet.ID, bazaar.ID}, noon, true)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if balances[wallet.ID].String() != "350" || balances[bazaar.ID].String() != "150" {
+			t.Errorf("mode %d: unexpected balances: %v", mode, balances)
+		}
+
+		if _, err := l.AccountBalanceAt("unknown", noon, true); err == nil {
+			t.Error("error expected for unknown account, nil found")
+		}
+	}
+}
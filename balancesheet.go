@@ -0,0 +1,168 @@
+package miser
+
+import (
+	"fmt"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+// Names of the Equity lines of balance sheet which are not accounts.
+const (
+	OpeningBalances     = "Equity:Opening balances"     // initial balances posted without counterpart
+	RetainedEarnings    = "Equity:Retained earnings"    // income minus expenses of the previous years
+	CurrentEarnings     = "Equity:Current earnings"     // income minus expenses since the start of the year
+	CurrencyTranslation = "Equity:Currency translation" // difference of conversion of foreign accounts at the date and at posting time
+)
+
+// BalanceSheet is a report of Assets, Liabilities and Equity as of the end of a date,
+// balances are converted to the currency of report at the date.
+type BalanceSheet struct {
+	Date     time.Time
+	Currency string
+
+	Assets, Liabilities, Equity                []ReportLine // single column of values
+	TotalAssets, TotalLiabilities, TotalEquity Amount
+}
+
+// Balanced: Assets = Liabilities + Equity, otherwise the balances of accounts
+// do not match their transactions (see Ledger.VerifyBalances).
+func (bs *BalanceSheet) Balanced() bool {
+	return bs.TotalAssets.Equal(bs.TotalLiabilities.Add(bs.TotalEquity))
+}
+
+// Balance sheet as of the end of given date in given currency. Earnings are shown as Equity lines:
+// retained ones of the previous years and the current ones since the start of the year of the date.
+func (l *Ledger) BalanceSheet(date time.Time, cur string) (*BalanceSheet, error) {
+	if l.cr.Get(cur) == nil {
+		return nil, fmt.Errorf("currency %q is not supproted", cur)
+	}
+
+	bs := &BalanceSheet{Date: date, Currency: cur}
+	end := nextDay(date)
+	yearStart := time.Date(date.Year(), time.January, 1, 0, 0, 0, 0, date.Location())
+
+	var assets, liabilities, equity []Account
+	var opening, retained, current, translation Amount
+	values := make(map[ID][]Amount)
+	for _, acc := range l.ar.List() {
+		accType := string(acc.Type)
+
+		if accType == Income || accType == Expense {
+			before, err := l.postingsTotal(&acc, cur, time.Time{}, yearStart)
+			if err != nil {
+				return nil, err
+			}
+			since, err := l.postingsTotal(&acc, cur, yearStart, end)
+			if err != nil {
+				return nil, err
+			}
+			if accType == Expense {
+				before, since = before.Neg(), since.Neg()
+			}
+			retained, current = retained.Add(before), current.Add(since)
+			continue
+		}
+
+		balance, err := l.AccountBalanceAt(acc.ID, end, false)
+		if err != nil {
+			return nil, err
+		}
+		if balance, err = l.Convert(balance, string(acc.Cur), cur, date); err != nil {
+			return nil, err
+		}
+		values[acc.ID] = []Amount{balance}
+
+		initial, err := l.initialBalances(&acc, cur, end)
+		if err != nil {
+			return nil, err
+		}
+		opening = opening.Add(equationSide(accType, initial))
+
+		// balance of foreign account converted at the date differs from its postings converted at their time:
+		if string(acc.Cur) != cur {
+			postings, err := l.postingsTotal(&acc, cur, time.Time{}, end)
+			if err != nil {
+				return nil, err
+			}
+			translation = translation.Add(equationSide(accType, balance.Sub(initial).Sub(postings)))
+		}
+
+		switch accType {
+		case Asset:
+			assets = append(assets, acc)
+		case Liability:
+			liabilities = append(liabilities, acc)
+		case Equity:
+			equity = append(equity, acc)
+		}
+	}
+
+	bs.Assets = reportTree(assets, values, 1)
+	bs.Liabilities = reportTree(liabilities, values, 1)
+	bs.Equity = reportTree(equity, values, 1)
+	bs.TotalAssets = reportTotals(bs.Assets, 1)[0]
+	bs.TotalLiabilities = reportTotals(bs.Liabilities, 1)[0]
+
+	for _, line := range []struct {
+		name  string
+		value Amount
+	}{
+		{OpeningBalances, opening},
+		{RetainedEarnings, retained},
+		{CurrentEarnings, current},
+		{CurrencyTranslation, translation},
+	} {
+		if !line.value.IsZero() {
+			bs.Equity = append(bs.Equity, ReportLine{Name: line.name, Depth: 1, Values: []Amount{line.value}})
+		}
+	}
+	bs.TotalEquity = reportTotals(bs.Equity, 1)[0]
+	return bs, nil
+}
+
+// Total of initial balances of account posted before given time, converted to given currency at their time.
+func (l *Ledger) initialBalances(acc *Account, cur string, end time.Time) (total Amount, err error) {
+	for _, t := range l.tr.Since(acc.ID, time.Time{}) {
+		if !t.Time.Before(end) {
+			break
+		}
+		if !t.IsInitial() {
+			continue
+		}
+		v, err := l.Convert(t.Value, string(acc.Cur), cur, t.Time)
+		if err != nil {
+			return Amount{}, err
+		}
+		total = total.Add(v)
+	}
+	return
+}
+
+// Render balance sheet as text table.
+func (bs *BalanceSheet) Render(digits int) string {
+	var sb strings.Builder
+	w := tabwriter.NewWriter(&sb, 0, 0, 2, ' ', 0)
+	rw := reportWriter{w: w, digits: digits}
+
+	fmt.Fprintf(w, "Balance sheet, %s\t%s\t\n", bs.Currency, bs.Date.Format(time.DateOnly))
+	rw.lines(bs.Assets)
+	rw.row("Total assets", []Amount{bs.TotalAssets})
+	rw.lines(bs.Liabilities)
+	rw.row("Total liabilities", []Amount{bs.TotalLiabilities})
+	rw.lines(bs.Equity)
+	rw.row("Total equity", []Amount{bs.TotalEquity})
+	rw.row("Total liabilities and equity", []Amount{bs.TotalLiabilities.Add(bs.TotalEquity)})
+
+	w.Flush()
+	return sb.String()
+}
+
+// Balance sheet as of the end of given date rendered as text.
+func (l *Ledger) BalanceSheetText(date time.Time, cur string) (string, error) {
+	bs, err := l.BalanceSheet(date, cur)
+	if err != nil {
+		return "", err
+	}
+	return bs.Render(l.reportDigits(cur)), nil
+}
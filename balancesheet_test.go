@@ -0,0 +1,182 @@
+package miser
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestBalanceSheet(t *testing.T) {
+	t.Parallel()
+
+	// Create repositories:
+	ar := CreateAccountRegistry()
+	tr := CreateTransactionRegistry()
+	br := CreateBalanceRegistry()
+	cr := CreateCurrencyRegistry()
+	tg := CreateTagRegistry()
+	tm := CreateTagsMapRegistry()
+
+	// Create service:
+	l := CreateLedger(ar, br, tr, cr, tg, tm)
+
+	openedAt := time.Date(2023, time.January, 1, 9, 0, 0, 0, time.UTC)
+	l.Rates().Create("EUR", "USD", openedAt, AmountFromInt(2))
+
+	accounts := make(map[string]*Account)
+	for _, a := range []struct {
+		name, accType, cur, v string
+	}{
+		{"Bank:Checking", Asset, "USD", "1000"},
+		{"Bank:Euro", Asset, "EUR", "1000"},
+		{"Card", Liability, "USD", "100"},
+		{"Capital", Equity, "USD", "500"},
+		{"Salary", Income, "USD", "100000"},
+		{"Groceries", Expense, "USD", "0"},
+		{"Travel", Expense, "EUR", "0"},
+	} {
+		acc, err := l.CreateAccount(a.name, a.accType, "", a.cur, openedAt, a.v)
+		if err != nil {
+			t.Fatal(err)
+		}
+		accounts[a.name] = acc
+	}
+
+	for _, d := range []struct {
+		src, dst string
+		date     time.Time
+		v        string
+	}{
+		{"Salary", "Bank:Checking", time.Date(2023, time.March, 25, 12, 0, 0, 0, time.UTC), "900"},
+		{"Bank:Checking", "Groceries", time.Date(2023, time.March, 26, 12, 0, 0, 0, time.UTC), "80"},
+		{"Salary", "Bank:Checking", time.Date(2024, time.March, 25, 12, 0, 0, 0, time.UTC), "1000"},
+		{"Bank:Checking", "Groceries", time.Date(2024, time.March, 1, 12, 0, 0, 0, time.UTC), "100"},
+		{"Card", "Groceries", time.Date(2024, time.March, 2, 12, 0, 0, 0, time.UTC), "40"},
+		{"Bank:Euro", "Travel", time.Date(2024, time.March, 10, 12, 0, 0, 0, time.UTC), "25"},
+		{"Bank:Checking", "Groceries", time.Date(2024, time.April, 1, 0, 0, 0, 0, time.UTC), "70"},
+	} {
+		if _, err := l.CreateTransaction(accounts[d.src].ID, accounts[d.dst].ID, d.date, d.v, ""); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	lineValues := func(bs *BalanceSheet) map[string]string {
+		m := make(map[string]string)
+		for _, lines := range [][]ReportLine{bs.Assets, bs.Liabilities, bs.Equity} {
+			for _, line := range lines {
+				m[line.Name] = line.Values[0].String()
+			}
+		}
+		return m
+	}
+
+	for _, c := range []struct {
+		name, date string
+		want       map[string]string
+		totals     [3]string
+	}{
+		{
+			"end of quarter", "2024-03-31",
+			map[string]string{
+				"Asset:Bank":          "4670",
+				"Asset:Bank:Checking": "2720",
+				"Asset:Bank:Euro":     "1950",
+				"Liability:Card":      "140",
+				"Equity:Capital":      "500",
+				OpeningBalances:       "2400",
+				RetainedEarnings:      "820",
+				CurrentEarnings:       "810",
+			},
+			[3]string{"4670", "140", "4530"},
+		},
+		{
+			"new rate", "2024-06-30",
+			map[string]string{
+				"Asset:Bank:Checking": "2650",
+				"Asset:Bank:Euro":     "2925",
+				OpeningBalances:       "2400",
+				RetainedEarnings:      "820",
+				CurrentEarnings:       "740",
+				CurrencyTranslation:   "975", // 975 EUR by 3 instead of 1000 EUR by 2 and -25 EUR by 2
+			},
+			[3]string{"5575", "140", "5435"},
+		},
+		{
+			"next year", "2025-01-15",
+			map[string]string{
+				RetainedEarnings:    "1560",
+				CurrencyTranslation: "975",
+			},
+			[3]string{"5575", "140", "5435"},
+		},
+	} {
+		if c.name == "new rate" {
+			l.Rates().Create("EUR", "USD", time.Date(2024, time.June, 1, 0, 0, 0, 0, time.UTC), AmountFromInt(3))
+		}
+
+		t.Run(c.name, func(t *testing.T) {
+			date, _ := time.Parse(time.DateOnly, c.date)
+			bs, err := l.BalanceSheet(date, "USD")
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			got := lineValues(bs)
+			for name, want := range c.want {
+				if got[name] != want {
+					t.Errorf("expected %s %s, got: %q", name, want, got[name])
+				}
+			}
+			if c.name == "next year" {
+				if _, ok := got[CurrentEarnings]; ok {
+					t.Errorf("expected no current earnings at the start of year, got: %s", got[CurrentEarnings])
+				}
+			}
+
+			totals := [3]string{bs.TotalAssets.String(), bs.TotalLiabilities.String(), bs.TotalEquity.String()}
+			if totals != c.totals {
+				t.Errorf("expected totals %v, got: %v", c.totals, totals)
+			}
+			if !bs.Balanced() {
+				t.Errorf("expected balanced sheet, got: %v", got)
+			}
+		})
+	}
+
+	t.Run("text", func(t *testing.T) {
+		text, err := l.BalanceSheetText(time.Date(2024, time.March, 31, 0, 0, 0, 0, time.UTC), "USD")
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, want := range []string{"2024-03-31", "  Checking", "Current earnings", "4670.00"} {
+			if !strings.Contains(text, want) {
+				t.Errorf("expected %q in text:\n%s", want, text)
+			}
+		}
+	})
+
+	t.Run("broken balance", func(t *testing.T) {
+		date := time.Date(2024, time.March, 31, 0, 0, 0, 0, time.UTC)
+		last := l.tr.FirstBefore(accounts["Bank:Checking"].ID, nextDay(date))
+		b := l.TransactionBalance(accounts["Bank:Checking"].ID, last.ID)
+		l.CreateBalance(b.Account, b.Transaction, b.Value.Add(AmountFromInt(500)))
+		defer l.CreateBalance(b.Account, b.Transaction, b.Value)
+
+		bs, err := l.BalanceSheet(date, "USD")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if bs.Balanced() {
+			t.Error("expected broken balance of account to unbalance the sheet")
+		}
+		if _, ok := lineValues(bs)[CurrencyTranslation]; ok {
+			t.Errorf("expected no currency translation, got: %v", lineValues(bs))
+		}
+	})
+
+	t.Run("unknown currency", func(t *testing.T) {
+		if _, err := l.BalanceSheet(time.Now(), "XXX"); err == nil {
+			t.Error("expected error of unknown currency")
+		}
+	})
+}
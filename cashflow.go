@@ -0,0 +1,264 @@
+package miser
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+// Name of tag line of cash flow for transactions without tags.
+const Untagged = "(untagged)"
+
+// CashFlow is a report of money coming into and going out of cash accounts over days From..To (inclusive),
+// transfers between the cash accounts are internal and not shown. Values are converted to the currency
+// of report: flows at their time, positions at the ends of periods.
+type CashFlow struct {
+	From, To time.Time
+	Currency string
+	Accounts []ID // the cash accounts
+
+	Opening, Closing Amount
+	In, Out          Amount         // totals of flows, both are positive
+	Translation      Amount         // change of positions made by exchange rates: Closing - Opening - In + Out
+	Counterparts     []CashFlowLine // flows by counterpart account, initial balances go to the Opening balances line
+	Tags             []CashFlowLine // flows by tag, transaction of several tags is counted in each of them
+	Months           []CashFlowPeriod
+}
+
+// CashFlowLine is a total of flows of counterpart account or tag.
+type CashFlowLine struct {
+	Name    string // full name of account or name of tag
+	In, Out Amount
+}
+
+// CashFlowPeriod is a part of cash flow over [Start, End).
+type CashFlowPeriod struct {
+	Start, End       time.Time
+	Opening, Closing Amount
+	In, Out          Amount
+}
+
+// Net flow: inflows minus outflows.
+func (cf *CashFlow) Net() Amount { return cf.In.Sub(cf.Out) }
+
+// Cash flow of given Asset accounts over days from..to (inclusive) in given currency, with monthly breakdown.
+func (l *Ledger) CashFlow(cash []ID, from, to time.Time, cur string) (*CashFlow, error) {
+	if l.cr.Get(cur) == nil {
+		return nil, fmt.Errorf("currency %q is not supproted", cur)
+	}
+	if len(cash) == 0 {
+		return nil, errors.New("no cash accounts")
+	}
+
+	months, err := periods(Monthly, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	cash = uniqueIDs(cash...)
+	isCash := make(map[ID]bool, len(cash))
+	for _, accID := range cash {
+		acc := l.ar.Get(accID)
+		if acc == nil {
+			return nil, fmt.Errorf("account not found: %s", accID)
+		}
+		if acc.Type != Asset {
+			return nil, fmt.Errorf("cash account should be an Asset: %s", acc.FullName())
+		}
+		isCash[accID] = true
+	}
+
+	cf := &CashFlow{From: from, To: to, Currency: cur, Accounts: cash}
+	for _, m := range months {
+		cf.Months = append(cf.Months, CashFlowPeriod{Start: m.Start, End: m.End})
+	}
+	cf.Months[0].Start = from // the first month is cut at the start of range
+
+	// positions at the start of the first period and at the ends of all periods:
+	cf.Months[0].Opening, err = l.cashPosition(cash, cur, from)
+	if err != nil {
+		return nil, err
+	}
+	for i := range cf.Months {
+		if i > 0 {
+			cf.Months[i].Opening = cf.Months[i-1].Closing
+		}
+		if cf.Months[i].Closing, err = l.cashPosition(cash, cur, cf.Months[i].End); err != nil {
+			return nil, err
+		}
+	}
+
+	counterparts := make(map[string]*CashFlowLine)
+	tags := make(map[string]*CashFlowLine)
+	add := func(lines map[string]*CashFlowLine, name string, in, out Amount) {
+		line := lines[name]
+		if line == nil {
+			line = &CashFlowLine{Name: name}
+			lines[name] = line
+		}
+		line.In, line.Out = line.In.Add(in), line.Out.Add(out)
+	}
+
+	end := nextDay(to)
+	for _, accID := range cash {
+		acc := l.ar.Get(accID)
+		for _, t := range l.tr.Since(accID, from) {
+			if !t.Time.Before(end) {
+				break
+			}
+
+			counterpart := OpeningBalances
+			switch {
+			case t.IsInitial():
+			case t.Source == accID && isCash[t.Dest], t.Dest == accID && isCash[t.Source]:
+				continue // internal transfer
+			case t.Source == accID:
+				counterpart = l.accountName(t.Dest)
+			default:
+				counterpart = l.accountName(t.Source)
+			}
+
+			operType := Debit
+			if t.Source == accID && !t.IsInitial() {
+				operType = Credit
+			}
+			v, err := l.Convert(effect(Asset, operType, t.Value), string(acc.Cur), cur, t.Time)
+			if err != nil {
+				return nil, err
+			}
+
+			var in, out Amount
+			if v.Sign() < 0 {
+				out = v.Neg()
+			} else {
+				in = v
+			}
+
+			cf.In, cf.Out = cf.In.Add(in), cf.Out.Add(out)
+			add(counterparts, counterpart, in, out)
+
+			names := l.reportTags(t)
+			if len(names) == 0 {
+				names = []string{Untagged}
+			}
+			for _, name := range names {
+				add(tags, name, in, out)
+			}
+
+			i, _ := slices.BinarySearchFunc(cf.Months, t.Time, func(p CashFlowPeriod, t time.Time) int {
+				if !p.End.After(t) {
+					return -1
+				}
+				return 1
+			})
+			cf.Months[i].In, cf.Months[i].Out = cf.Months[i].In.Add(in), cf.Months[i].Out.Add(out)
+		}
+	}
+
+	cf.Opening = cf.Months[0].Opening
+	cf.Closing = cf.Months[len(cf.Months)-1].Closing
+	cf.Translation = cf.Closing.Sub(cf.Opening).Sub(cf.Net())
+	cf.Counterparts = sortedCashFlowLines(counterparts)
+	cf.Tags = sortedCashFlowLines(tags)
+	return cf, nil
+}
+
+// Total balance of accounts before given time converted to given currency.
+func (l *Ledger) cashPosition(accounts []ID, cur string, end time.Time) (total Amount, err error) {
+	for _, accID := range accounts {
+		v, err := l.AccountBalanceAt(accID, end, false)
+		if err != nil {
+			return Amount{}, err
+		}
+		if v.IsZero() {
+			continue
+		}
+		if v, err = l.Convert(v, string(l.ar.Get(accID).Cur), cur, end.Add(-time.Nanosecond)); err != nil {
+			return Amount{}, err
+		}
+		total = total.Add(v)
+	}
+	return
+}
+
+func sortedCashFlowLines(lines map[string]*CashFlowLine) []CashFlowLine {
+	sorted := make([]CashFlowLine, 0, len(lines))
+	for _, line := range lines {
+		sorted = append(sorted, *line)
+	}
+	slices.SortFunc(sorted, func(a, b CashFlowLine) int { return cmp.Compare(a.Name, b.Name) })
+	return sorted
+}
+
+// Full name of account, or its ID if the account is not found.
+func (l *Ledger) accountName(accID ID) string {
+	if acc := l.ar.Get(accID); acc != nil {
+		return acc.FullName()
+	}
+	return string(accID)
+}
+
+// Sorted names of tags of transaction.
+func (l *Ledger) transactionTags(trID ID) (names []string) {
+	for _, tagID := range l.tm.Tags(trID) {
+		tag := l.tg.GetById(tagID)
+		if tag != nil && !tag.Deleted && l.tm.Has(tagID, trID) {
+			names = append(names, string(tag.Name))
+		}
+	}
+	slices.Sort(names)
+	return
+}
+
+// Tags of transaction in reports: the reversing entry is counted under tags of the voided transaction,
+// so they cancel each other out.
+func (l *Ledger) reportTags(t Transaction) []string {
+	if t.Voids != "" {
+		return l.transactionTags(t.Voids)
+	}
+	return l.transactionTags(t.ID)
+}
+
+// Render cash flow as text table.
+func (cf *CashFlow) Render(digits int) string {
+	var sb strings.Builder
+	w := tabwriter.NewWriter(&sb, 0, 0, 2, ' ', 0)
+	rw := reportWriter{w: w, digits: digits}
+
+	fmt.Fprintf(w, "Cash flow, %s, %s..%s\n", cf.Currency, cf.From.Format(time.DateOnly), cf.To.Format(time.DateOnly))
+	rw.row("Opening", []Amount{cf.Opening})
+	fmt.Fprintln(w, "Counterpart\tIn\tOut\t")
+	for _, line := range cf.Counterparts {
+		rw.row("  "+line.Name, []Amount{line.In, line.Out})
+	}
+	fmt.Fprintln(w, "Tag\tIn\tOut\t")
+	for _, line := range cf.Tags {
+		rw.row("  "+line.Name, []Amount{line.In, line.Out})
+	}
+	rw.row("Total", []Amount{cf.In, cf.Out})
+	if !cf.Translation.IsZero() {
+		rw.row("Exchange rates", []Amount{cf.Translation})
+	}
+	rw.row("Closing", []Amount{cf.Closing})
+
+	fmt.Fprintln(w, "Month\tOpening\tIn\tOut\tClosing\t")
+	for _, m := range cf.Months {
+		rw.row(m.Start.Format("2006-01"), []Amount{m.Opening, m.In, m.Out, m.Closing})
+	}
+
+	w.Flush()
+	return sb.String()
+}
+
+// Cash flow of given accounts over days from..to rendered as text.
+func (l *Ledger) CashFlowText(cash []ID, from, to time.Time, cur string) (string, error) {
+	cf, err := l.CashFlow(cash, from, to, cur)
+	if err != nil {
+		return "", err
+	}
+	return cf.Render(l.reportDigits(cur)), nil
+}
@@ -0,0 +1,199 @@
+package miser
+
+import (
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCashFlow(t *testing.T) {
+	t.Parallel()
+
+	// Create repositories:
+	ar := CreateAccountRegistry()
+	tr := CreateTransactionRegistry()
+	br := CreateBalanceRegistry()
+	cr := CreateCurrencyRegistry()
+	tg := CreateTagRegistry()
+	tm := CreateTagsMapRegistry()
+
+	// Create service:
+	l := CreateLedger(ar, br, tr, cr, tg, tm)
+	if err := l.SetBalanceMode(DerivedBalances); err != nil {
+		t.Fatal(err)
+	}
+
+	openedAt := time.Date(2024, time.January, 1, 9, 0, 0, 0, time.UTC)
+	l.Rates().Create("EUR", "USD", openedAt, AmountFromInt(2))
+	l.Rates().Create("EUR", "USD", time.Date(2024, time.March, 1, 0, 0, 0, 0, time.UTC), AmountFromInt(3))
+
+	accounts := make(map[string]*Account)
+	for _, a := range []struct {
+		name, accType, cur, v string
+	}{
+		{"Bank", Asset, "USD", "1000"},
+		{"Wallet", Asset, "USD", "100"},
+		{"Euro", Asset, "EUR", "100"},
+		{"Savings", Asset, "USD", "0"},
+		{"Salary", Income, "USD", "100000"},
+		{"Groceries", Expense, "USD", "0"},
+		{"Travel", Expense, "EUR", "0"},
+	} {
+		acc, err := l.CreateAccount(a.name, a.accType, "", a.cur, openedAt, a.v)
+		if err != nil {
+			t.Fatal(err)
+		}
+		accounts[a.name] = acc
+	}
+
+	for _, d := range []struct {
+		src, dst string
+		date     time.Time
+		v        string
+		tags     []string
+	}{
+		{"Salary", "Bank", time.Date(2024, time.January, 10, 12, 0, 0, 0, time.UTC), "2000", []string{"work"}},
+		{"Bank", "Wallet", time.Date(2024, time.January, 12, 12, 0, 0, 0, time.UTC), "200", nil},
+		{"Wallet", "Groceries", time.Date(2024, time.January, 15, 12, 0, 0, 0, time.UTC), "50", []string{"food", "home"}},
+		{"Bank", "Savings", time.Date(2024, time.February, 5, 12, 0, 0, 0, time.UTC), "500", nil},
+		{"Euro", "Travel", time.Date(2024, time.February, 10, 12, 0, 0, 0, time.UTC), "20", nil},
+		{"Bank", "Groceries", time.Date(2024, time.March, 5, 12, 0, 0, 0, time.UTC), "100", []string{"food"}},
+		{"Bank", "Groceries", time.Date(2024, time.April, 2, 12, 0, 0, 0, time.UTC), "30", []string{"food"}},
+	} {
+		src, dst := accounts[d.src], accounts[d.dst]
+		if src.Type == dst.Type {
+			// the ledger does not make transfers between accounts of the same type,
+			// but they can come from the journal, e.g. written by other tools:
+			tr.Add(Transaction{ID: CreateID(), Source: src.ID, Dest: dst.ID, Time: d.date,
+				Value: MustParseAmount(d.v), Seq: tr.NextSeq()})
+			continue
+		}
+
+		created, err := l.CreateTransaction(src.ID, dst.ID, d.date, d.v, "")
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, name := range d.tags {
+			l.tagItem(name, created.ID)
+		}
+	}
+
+	cash := []ID{accounts["Bank"].ID, accounts["Wallet"].ID, accounts["Euro"].ID}
+	from := time.Date(2024, time.January, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2024, time.March, 31, 0, 0, 0, 0, time.UTC)
+	cf, err := l.CashFlow(cash, from, to, "USD")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("totals", func(t *testing.T) {
+		got := []string{cf.Opening.String(), cf.In.String(), cf.Out.String(), cf.Translation.String(), cf.Closing.String()}
+		if want := []string{"0", "3300", "690", "80", "2690"}; !slices.Equal(got, want) {
+			t.Errorf("expected opening, in, out, translation, closing %v, got: %v", want, got)
+		}
+		if !cf.Opening.Add(cf.Net()).Add(cf.Translation).Equal(cf.Closing) {
+			t.Error("expected closing position to be equal to opening one plus flows")
+		}
+	})
+
+	lines := func(lines []CashFlowLine) (s []string) {
+		for _, line := range lines {
+			s = append(s, line.Name+" "+line.In.String()+" "+line.Out.String())
+		}
+		return
+	}
+
+	t.Run("counterparts", func(t *testing.T) {
+		want := []string{
+			"Asset:Savings 0 500",
+			OpeningBalances + " 1300 0",
+			"Expense:Groceries 0 150",
+			"Expense:Travel 0 40",
+			"Income:Salary 2000 0",
+		}
+		if got := lines(cf.Counterparts); !slices.Equal(got, want) {
+			t.Errorf("expected counterparts %v, got: %v", want, got)
+		}
+	})
+
+	t.Run("tags", func(t *testing.T) {
+		want := []string{
+			Untagged + " 0 540",
+			"Initial 1300 0",
+			"food 0 150",
+			"home 0 50",
+			"work 2000 0",
+		}
+		if got := lines(cf.Tags); !slices.Equal(got, want) {
+			t.Errorf("expected tags %v, got: %v", want, got)
+		}
+	})
+
+	t.Run("months", func(t *testing.T) {
+		var got []string
+		for _, m := range cf.Months {
+			got = append(got, strings.Join([]string{
+				m.Start.Format(time.DateOnly), m.Opening.String(), m.In.String(), m.Out.String(), m.Closing.String()}, " "))
+		}
+		want := []string{
+			"2024-01-01 0 3300 50 3250",
+			"2024-02-01 3250 0 540 2710",
+			"2024-03-01 2710 0 100 2690",
+		}
+		if !slices.Equal(got, want) {
+			t.Errorf("expected months %v, got: %v", want, got)
+		}
+	})
+
+	t.Run("text", func(t *testing.T) {
+		text, err := l.CashFlowText(cash, from, to, "USD")
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, want := range []string{"2024-01-01..2024-03-31", "Income:Salary", "Exchange rates", "2690.00"} {
+			if !strings.Contains(text, want) {
+				t.Errorf("expected %q in text:\n%s", want, text)
+			}
+		}
+	})
+
+	t.Run("voided", func(t *testing.T) {
+		orig, err := l.CreateTransaction(accounts["Wallet"].ID, accounts["Groceries"].ID,
+			time.Date(2024, time.March, 10, 12, 0, 0, 0, time.UTC), "20", "")
+		if err != nil {
+			t.Fatal(err)
+		}
+		l.tagItem("food", orig.ID)
+		if _, err := l.VoidTransaction(orig.ID, time.Date(2024, time.March, 12, 12, 0, 0, 0, time.UTC)); err != nil {
+			t.Fatal(err)
+		}
+
+		cf, err := l.CashFlow(cash, from, to, "USD")
+		if err != nil {
+			t.Fatal(err)
+		}
+		want := []string{
+			Untagged + " 0 540",
+			"Initial 1300 0",
+			"food 20 170",
+			"home 0 50",
+			"work 2000 0",
+		}
+		if got := lines(cf.Tags); !slices.Equal(got, want) {
+			t.Errorf("expected reversing entry under tags of voided one %v, got: %v", want, got)
+		}
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		if _, err := l.CashFlow(nil, from, to, "USD"); err == nil {
+			t.Error("expected error of no cash accounts")
+		}
+		if _, err := l.CashFlow([]ID{accounts["Salary"].ID}, from, to, "USD"); err == nil {
+			t.Error("expected error of not Asset account")
+		}
+		if _, err := l.CashFlow(cash, to, from, "USD"); err == nil {
+			t.Error("expected error of wrong period")
+		}
+	})
+}
@@ -38,7 +38,6 @@ func main() {
 	fmt.Println(strings.Repeat("---", 40))
 	fmt.Printf("%d balances loaded, err: %v\n", n, err)
 	fmt.Printf("Balances: %#v\n", br.List())
-	//	fmt.Println("check balance:", miser.CheckBalance())
 
 	// load tags:
 	n, err = tg.Load()
@@ -51,15 +50,91 @@ func main() {
 	fmt.Println(strings.Repeat("---", 40))
 	fmt.Printf("%d tags map loaded, err: %v\n", n, err)
 
+	// load user-defined commodities:
+	n, err = cr.Load()
+	fmt.Println(strings.Repeat("---", 40))
+	fmt.Printf("%d commodities loaded, err: %v\n", n, err)
+
+	// load reconciliations:
+	n, err = l.Reconciliations().Load()
+	fmt.Println(strings.Repeat("---", 40))
+	fmt.Printf("%d reconciliations loaded, err: %v\n", n, err)
+
+	// load schedules of recurring transactions:
+	n, err = l.Schedules().Load()
+	fmt.Println(strings.Repeat("---", 40))
+	fmt.Printf("%d schedules loaded, err: %v\n", n, err)
+
+	// load exchange rates:
+	n, err = l.Rates().Load()
+	fmt.Println(strings.Repeat("---", 40))
+	fmt.Printf("%d exchange rates loaded, err: %v\n", n, err)
+
+	// load saved queries:
+	n, err = l.Queries().Load()
+	fmt.Println(strings.Repeat("---", 40))
+	fmt.Printf("%d saved queries loaded, err: %v\n", n, err)
+
+	// verify the balance journal against transactions:
+	fmt.Println(strings.Repeat("---", 40))
+	fmt.Printf("%d balances differ from transactions\n", len(l.VerifyBalances()))
+
+	// load balance assertions and check them:
+	n, err = l.Assertions().Load()
+	fmt.Println(strings.Repeat("---", 40))
+	fmt.Printf("%d balance assertions loaded, err: %v\n", n, err)
+	results, err := l.CheckAssertions()
+	if err != nil {
+		fmt.Println(err)
+	}
+	for _, r := range results {
+		if !r.Ok() {
+			fmt.Printf("balance assertion failed: %s at %s should be %s, got %s\n",
+				r.Account, r.Date.Format(time.DateOnly), r.Value, r.Actual)
+		}
+	}
+
+	// analyze loaded transactions:
+	res, err := l.Analyze(miser.DefaultAnalysisOptions())
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+	fmt.Println(strings.Repeat("---", 40))
+	fmt.Printf("%d periodic, %d over average transactions tagged\n", len(res.Periodic), len(res.OverAverage))
+
+	// learn spendings to detect unexpected ones:
+	l.LearnSpendings()
+	unexpected := l.Unexpected(time.Now().AddDate(0, 0, -7), time.Now())
+	fmt.Printf("%d unexpected transactions during the last week\n", len(unexpected))
+
+	// query transactions, e.g.: miser 'acct:Expense date:2024-03 not:state:cleared'
+	if len(os.Args) > 1 {
+		q, err := l.ParseQuery(strings.Join(os.Args[1:], " "))
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+		page, err := l.Query(q)
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+		fmt.Println(strings.Repeat("---", 40))
+		for _, t := range page.Items {
+			fmt.Printf("%s %s %s\n", t.Time.Format(time.DateOnly), l.AmountTransaction(&t), t.Text)
+		}
+	}
+
 	ac1, err := l.CreateAccount(
-		"SMBC Trust Bank", miser.Asset, "Salary account", "JPY", time.Now(), 1555.13)
+		"SMBC Trust Bank", miser.Asset, "Salary account", "JPY", time.Now(), "1555")
 	if err != nil {
 		fmt.Println(err)
 		os.Exit(1)
 	}
 
 	Aeon, err := l.CreateAccount(
-		"AEON Supermarket", miser.Expense, "work bank account", "JPY", time.Now(), 0)
+		"AEON Supermarket", miser.Expense, "work bank account", "JPY", time.Now(), "0")
 	if err != nil {
 		fmt.Println(err)
 		os.Exit(1)
@@ -85,7 +160,7 @@ func main() {
 	ac1B := l.AccountAmount(ac1.ID)
 	fmt.Printf("Balance of SMBC before transaction: %.2f\n", ac1B)
 
-	t1, err := l.CreateTransaction(ac1.ID, Aeon.ID, time.Now(), 112.56, "私は店に行き、卵2kgと小麦粉を買いました。")
+	t1, err := l.CreateTransaction(ac1.ID, Aeon.ID, time.Now(), "112", "私は店に行き、卵2kgと小麦粉を買いました。")
 	if err != nil {
 		fmt.Println("create transaction failure:", err)
 		os.Exit(1)
@@ -113,7 +188,44 @@ func main() {
 	fmt.Printf("\n%#v\n", t1e)
 	fmt.Println("Amount:", l.AmountTransaction(t1))
 	fmt.Println("Balances:", br)
-	// fmt.Println("check balance:", miser.CheckBalance())
+
+	tb := l.TrialBalance(time.Now())
+	fmt.Printf("Trial balance: %v, imbalance: %s\n", tb.Totals, tb.Imbalance)
+	for _, c := range tb.Culprits {
+		fmt.Printf("  %s %s: %s (%s)\n", c.Account, c.Transaction, c.Amount, c.Reason)
+	}
+
+	now := time.Now()
+	monthStart := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, now.Location())
+	text, err := l.IncomeStatementText(monthStart, monthStart.AddDate(0, 1, -1), "JPY")
+	if err != nil {
+		fmt.Println(err)
+	}
+	fmt.Print(text)
+
+	text, err = l.BalanceSheetText(now, "JPY")
+	if err != nil {
+		fmt.Println(err)
+	}
+	fmt.Print(text)
+
+	text, err = l.CashFlowText([]miser.ID{ac1.ID}, monthStart, now, "JPY")
+	if err != nil {
+		fmt.Println(err)
+	}
+	fmt.Print(text)
+
+	text, err = l.RegisterText(ac1.ID, "")
+	if err != nil {
+		fmt.Println(err)
+	}
+	fmt.Print(text)
+
+	text, err = l.TagSpendingText(now.AddDate(0, -2, 0), now, "JPY", miser.SplitAmongTags)
+	if err != nil {
+		fmt.Println(err)
+	}
+	fmt.Print(text)
 
 	n, err = ar.Save()
 	fmt.Printf("%d new accounts saved, err: %v\n", n, err)
@@ -125,4 +237,16 @@ func main() {
 	fmt.Printf("%d tags saved, err: %v\n", n, err)
 	n, err = tm.Save()
 	fmt.Printf("%d tags map saved, err: %v\n", n, err)
+	n, err = cr.Save()
+	fmt.Printf("%d commodities saved, err: %v\n", n, err)
+	n, err = l.Reconciliations().Save()
+	fmt.Printf("%d reconciliations saved, err: %v\n", n, err)
+	n, err = l.Schedules().Save()
+	fmt.Printf("%d schedules saved, err: %v\n", n, err)
+	n, err = l.Rates().Save()
+	fmt.Printf("%d exchange rates saved, err: %v\n", n, err)
+	n, err = l.Queries().Save()
+	fmt.Printf("%d saved queries saved, err: %v\n", n, err)
+	n, err = l.Assertions().Save()
+	fmt.Printf("%d balance assertions saved, err: %v\n", n, err)
 }
@@ -3,28 +3,154 @@ package miser
 import (
 	_ "embed"
 	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"unicode"
 )
 
 //go:embed currency.json
 var currencyJsonContent []byte
 
-type Currency struct{ Code, Name, Sign string }
+type Currency struct {
+	Code, Name, Sign string
+	MinorUnits       int // number of fraction digits, ISO 4217 exponent
+}
+
+// Number of fraction digits of currency amounts, no more than AmountDigits.
+func (c *Currency) Digits() int { return min(c.MinorUnits, AmountDigits) }
 
-type CurrencyRegistry map[string]Currency
+// Parse decimal string amount of currency, round it to minor units.
+func (c *Currency) Parse(s string) (Amount, error) { return ParseAmount(s, c.Digits()) }
 
-func (cr CurrencyRegistry) Get(code string) *Currency {
-	c, ok := cr[code]
-	if !ok {
-		return nil
+// Format amount with currency sign and digits, e.g. "$ 12.50", "¥ 1235".
+func (c *Currency) Format(a Amount) string {
+	if c.Sign == "" {
+		return fmt.Sprintf("%s %s", a.Format(c.Digits()), c.Code)
 	}
-	return &c
+	return fmt.Sprintf("%s %s", c.Sign, a.Format(c.Digits()))
+}
+
+// Commodity is user-defined currency: crypto token, airline miles,
+// stock ticker, gift-card points etc.
+type Commodity struct {
+	ID               ID
+	Code, Name, Sign EncryptedString
+	MinorUnits       int // precision, number of fraction digits
+	Deleted          bool
+}
+
+func (c Commodity) Currency() Currency {
+	return Currency{Code: string(c.Code), Name: string(c.Name), Sign: string(c.Sign), MinorUnits: c.MinorUnits}
+}
+
+// CurrencyRegistry holds currencies of embedded currency.json
+// and user-defined commodities.
+type CurrencyRegistry struct {
+	builtin map[string]Currency
+	items   map[ID]Commodity
+	queued  map[ID]Commodity
+
+	sync.RWMutex
+}
+
+func (cr *CurrencyRegistry) Get(code string) *Currency {
+	cr.RLock()
+	defer cr.RUnlock()
+
+	if c, ok := cr.builtin[code]; ok {
+		return &c
+	}
+	if cm := cr.commodity(code); cm != nil {
+		c := cm.Currency()
+		return &c
+	}
+	return nil
+}
+
+func (cr *CurrencyRegistry) commodity(code string) *Commodity {
+	for _, c := range cr.items {
+		if !c.Deleted && string(c.Code) == code {
+			return &c
+		}
+	}
+	return nil
+}
+
+// List user-defined commodities.
+func (cr *CurrencyRegistry) List() map[ID]Commodity {
+	cr.RLock()
+	defer cr.RUnlock()
+	return cr.items
+}
+
+// Create a new commodity, its code should be unique
+// among both built-in currencies and other commodities.
+func (cr *CurrencyRegistry) Create(code, name, sign string, minorUnits int) (*Commodity, error) {
+	code, name = strings.TrimSpace(code), strings.TrimSpace(name)
+	if code == "" {
+		return nil, errors.New("code of commodity is blank")
+	}
+	if strings.ContainsFunc(code, unicode.IsSpace) {
+		return nil, fmt.Errorf("code of commodity %q contains spaces", code)
+	}
+	if name == "" {
+		return nil, errors.New("name of commodity is blank")
+	}
+	if minorUnits < 0 || minorUnits > AmountDigits {
+		return nil, fmt.Errorf("precision of commodity should be between 0 and %d", AmountDigits)
+	}
+	if cr.Get(code) != nil {
+		return nil, fmt.Errorf("currency %q already exists", code)
+	}
+
+	c := Commodity{
+		ID:         CreateID(),
+		Code:       EncryptedString(code),
+		Name:       EncryptedString(name),
+		Sign:       EncryptedString(sign),
+		MinorUnits: minorUnits,
+	}
+	cr.Add(c)
+	cr.AddQueued(c)
+	return &c, nil
+}
+
+func (cr *CurrencyRegistry) Add(c Commodity) int {
+	cr.Lock()
+	defer cr.Unlock()
+	cr.items[c.ID] = c
+	return 1
+}
+
+func (cr *CurrencyRegistry) AddQueued(c Commodity) {
+	cr.Lock()
+	defer cr.Unlock()
+	cr.queued[c.ID] = c
+}
+
+func (cr *CurrencyRegistry) SyncQueued() (changes []Commodity) {
+	cr.RLock()
+	defer cr.RUnlock()
+	for _, c := range cr.queued {
+		changes = append(changes, c)
+	}
+	return
 }
 
 func CreateCurrencyRegistry() *CurrencyRegistry {
-	cr := make(CurrencyRegistry)
-	err := json.Unmarshal(currencyJsonContent, &cr)
+	cr := CurrencyRegistry{
+		builtin: make(map[string]Currency),
+		items:   make(map[ID]Commodity),
+		queued:  make(map[ID]Commodity),
+	}
+	err := json.Unmarshal(currencyJsonContent, &cr.builtin)
 	if err != nil {
 		panic(err)
 	}
 	return &cr
 }
+
+func (cr *CurrencyRegistry) Load() (int, error) { return Load(cr, COMMODITIES_FILE) }
+func (cr *CurrencyRegistry) Save() (int, error) { return Save(cr, COMMODITIES_FILE) }
@@ -2,6 +2,7 @@ package miser
 
 import (
 	"testing"
+	"time"
 )
 
 func TestCurrency(t *testing.T) {
@@ -17,3 +18,92 @@ func TestCurrency(t *testing.T) {
 		t.Errorf("expected usd, got: %#v", c)
 	}
 }
+
+func TestCurrencyFormat(t *testing.T) {
+	t.Parallel()
+
+	cr := CreateCurrencyRegistry()
+
+	cases := []struct {
+		code, s, want string
+	}{
+		{"USD", "12.5", "$ 12.50"},
+		{"JPY", "1234.5", "¥ 1235"},
+		{"BTC", "0.123456789", "₿ 0.12345679"},
+		{"BNB", "0.25", "0.250000000000000000 BNB"},
+	}
+	for _, c := range cases {
+		cur := cr.Get(c.code)
+		a, err := cur.Parse(c.s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if s := cur.Format(a); s != c.want {
+			t.Errorf("expected %q, got: %q", c.want, s)
+		}
+	}
+}
+
+func TestCommodity(t *testing.T) {
+	t.Parallel()
+
+	// Create repositories:
+	ar := CreateAccountRegistry()
+	tr := CreateTransactionRegistry()
+	br := CreateBalanceRegistry()
+	cr := CreateCurrencyRegistry()
+	tg := CreateTagRegistry()
+	tm := CreateTagsMapRegistry()
+
+	// Create service:
+	l := CreateLedger(ar, br, tr, cr, tg, tm)
+
+	t.Run("create", func(t *testing.T) {
+		c, err := cr.Create("MILES", "Airline miles", "✈", 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		cur := cr.Get("MILES")
+		if cur == nil {
+			t.Fatal("commodity not found, code: MILES")
+		}
+		if cur.Name != string(c.Name) || cur.MinorUnits != 0 {
+			t.Errorf("unexpected currency of commodity: %#v", cur)
+		}
+
+		acc, err := l.CreateAccount("Miles", Asset, "frequent flyer", "MILES", time.Now(), "12500.7")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if b := l.AccountBalance(acc.ID); b.Value.String() != "12501" {
+			t.Errorf("expected 12501 miles, got: %s", b.Value)
+		}
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		cases := []struct {
+			code, name string
+			precision  int
+		}{
+			{"", "blank code", 2},
+			{"GIFT CARD", "space in code", 2},
+			{"PTS", "", 2},
+			{"PTS", "too precise", AmountDigits + 1},
+			{"PTS", "negative precision", -1},
+			{"USD", "built-in currency", 2},
+		}
+		for _, c := range cases {
+			if _, err := cr.Create(c.code, c.name, "", c.precision); err == nil {
+				t.Errorf("%s: error expected, nil found", c.name)
+			}
+		}
+
+		if _, err := cr.Create("AAPL", "Apple Inc.", "", 4); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := cr.Create("AAPL", "duplicate", "", 4); err == nil {
+			t.Error("error expected for duplicate code, nil found")
+		}
+	})
+}
@@ -0,0 +1,123 @@
+package miser
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+// Modes of running balances of accounts.
+const (
+	JournalBalances = iota // written to the balance journal by every posting, the default
+	DerivedBalances        // computed from transactions, the balance journal is not used
+)
+
+// Running balances of account derived from its ordered transactions.
+type derivedBalances struct {
+	version uint64     // version of index of account transactions
+	pos     map[ID]int // position of transaction
+	values  []Amount   // balance after transaction at position
+}
+
+type balanceCache struct {
+	items    map[ID]*derivedBalances
+	disabled bool
+
+	sync.Mutex
+}
+
+func createBalanceCache() *balanceCache {
+	return &balanceCache{items: make(map[ID]*derivedBalances)}
+}
+
+// Set mode of running balances. Switching to the journal mode brings
+// the balance journal up to date with transactions.
+func (l *Ledger) SetBalanceMode(mode int) error {
+	switch mode {
+	case DerivedBalances:
+	case JournalBalances:
+		if l.mode != JournalBalances {
+			l.mode = mode
+			for accID := range l.ar.List() {
+				if err := l.rebalance(accID, time.Time{}); err != nil {
+					return err
+				}
+			}
+		}
+	default:
+		return fmt.Errorf("wrong mode of balances: %d", mode)
+	}
+	l.mode = mode
+	return nil
+}
+
+func (l *Ledger) BalanceMode() int { return l.mode }
+
+// Enable or disable cache of derived balances, without it balances
+// are computed on every request.
+func (l *Ledger) SetBalanceCache(enabled bool) {
+	l.bc.Lock()
+	defer l.bc.Unlock()
+	l.bc.disabled = !enabled
+	clear(l.bc.items)
+}
+
+// Balance of account after the transaction.
+func (l *Ledger) TransactionBalance(accID, trID ID) *Balance {
+	if l.mode == JournalBalances {
+		return l.br.TransactionBalance(accID, trID)
+	}
+
+	v, ok := l.derivedBalance(accID, trID)
+	if !ok {
+		return nil
+	}
+	return &Balance{Account: accID, Transaction: trID, Value: v}
+}
+
+// Balance of account after the transaction computed from transactions regardless of mode.
+func (l *Ledger) derivedBalance(accID, trID ID) (Amount, bool) {
+	d := l.derive(accID)
+	if d == nil {
+		return Amount{}, false
+	}
+	i, ok := d.pos[trID]
+	if !ok {
+		return Amount{}, false
+	}
+	return d.values[i], true
+}
+
+// Running balances of account, they are recomputed if its transactions are changed.
+func (l *Ledger) derive(accID ID) *derivedBalances {
+	acc := l.ar.Get(accID)
+	if acc == nil {
+		return nil
+	}
+
+	l.bc.Lock()
+	defer l.bc.Unlock()
+
+	// the cheap check of version first, transactions are copied only on a miss:
+	if d := l.bc.items[accID]; d != nil && d.version == l.tr.version(accID) {
+		return d
+	}
+
+	version, trs := l.tr.snapshot(accID)
+	d := &derivedBalances{
+		version: version,
+		pos:     make(map[ID]int, len(trs)),
+		values:  make([]Amount, len(trs)),
+	}
+
+	var value Amount
+	for i, t := range trs {
+		value = runningBalance(acc, value, t)
+		d.pos[t.ID], d.values[i] = i, value
+	}
+
+	if !l.bc.disabled {
+		l.bc.items[accID] = d
+	}
+	return d
+}
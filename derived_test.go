@@ -0,0 +1,150 @@
+package miser
+
+import (
+	"testing"
+	"time"
+)
+
+// Post the same history to ledger: back-dated, updated, deleted and reordered transactions.
+func postHistory(t *testing.T, l *Ledger) (bank, shop, salary *Account) {
+	t.Helper()
+
+	openedAt := time.Date(2024, time.January, 1, 0, 0, 0, 0, time.UTC)
+	bank, err := l.CreateAccount("Bank", Asset, "checking", "USD", openedAt, "1000")
+	if err != nil {
+		t.Fatal(err)
+	}
+	shop, err = l.CreateAccount("Shop", Expense, "groceries", "USD", openedAt, "0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	salary, err = l.CreateAccount("Salary", Income, "", "USD", openedAt, "10000")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var ids []ID
+	post := func(src, dst ID, day int, v string) {
+		transa, err := l.CreateTransaction(src, dst, openedAt.AddDate(0, 0, day), v, "")
+		if err != nil {
+			t.Fatal(err)
+		}
+		ids = append(ids, transa.ID)
+	}
+
+	post(bank.ID, shop.ID, 10, "100")
+	post(salary.ID, bank.ID, 20, "2000")
+	post(bank.ID, shop.ID, 30, "300")
+	post(bank.ID, shop.ID, 5, "50") // back-dated
+	post(bank.ID, shop.ID, 30, "30")
+
+	if _, err := l.UpdateTransaction(ids[0], bank.ID, shop.ID, openedAt.AddDate(0, 0, 25), "120", ""); err != nil {
+		t.Fatal(err)
+	}
+	if err := l.DeleteTransaction(ids[2]); err != nil {
+		t.Fatal(err)
+	}
+	post(bank.ID, shop.ID, 30, "70")
+	if err := l.ReorderTransactions(ids[5], ids[4]); err != nil {
+		t.Fatal(err)
+	}
+	return
+}
+
+func TestDerivedBalances(t *testing.T) {
+	t.Parallel()
+
+	// Create ledger with the balance journal:
+	l := CreateLedger(CreateAccountRegistry(), CreateBalanceRegistry(), CreateTransactionRegistry(),
+		CreateCurrencyRegistry(), CreateTagRegistry(), CreateTagsMapRegistry())
+	postHistory(t, l)
+
+	// Create ledger with derived balances:
+	d := CreateLedger(CreateAccountRegistry(), CreateBalanceRegistry(), CreateTransactionRegistry(),
+		CreateCurrencyRegistry(), CreateTagRegistry(), CreateTagsMapRegistry())
+	if err := d.SetBalanceMode(DerivedBalances); err != nil {
+		t.Fatal(err)
+	}
+	bank, shop, salary := postHistory(t, d)
+
+	t.Run("equivalence", func(t *testing.T) {
+		for accID := range l.ar.List() {
+			for _, transa := range l.tr.Since(accID, time.Time{}) {
+				v, ok := l.derivedBalance(accID, transa.ID)
+				if b := l.br.TransactionBalance(accID, transa.ID); !ok || b == nil || !b.Value.Equal(v) {
+					t.Errorf("balance of %s after %s: journal %v, derived %s", accID, transa.Value, b, v)
+				}
+			}
+		}
+	})
+
+	for acc, want := range map[*Account]string{bank: "2730", shop: "270", salary: "12000"} {
+		if s := d.AccountBalance(acc.ID).Value.String(); s != want {
+			t.Errorf("expected %s of %s, got: %s", want, acc.Name, s)
+		}
+	}
+
+	if n := len(d.br.List()); n != 0 {
+		t.Errorf("expected empty balance journal, got %d balances", n)
+	}
+
+	t.Run("cache", func(t *testing.T) {
+		if _, err := d.CreateTransaction(bank.ID, shop.ID, time.Date(2024, time.January, 2, 0, 0, 0, 0, time.UTC), "10", ""); err != nil {
+			t.Fatal(err)
+		}
+		if s := d.AccountBalance(bank.ID).Value.String(); s != "2720" {
+			t.Errorf("expected recomputed balance 2720, got: %s", s)
+		}
+
+		d.SetBalanceCache(false)
+		if s := d.AccountBalance(shop.ID).Value.String(); s != "280" {
+			t.Errorf("expected balance 280 without cache, got: %s", s)
+		}
+		if n := len(d.bc.items); n != 0 {
+			t.Errorf("expected empty cache, got: %d", n)
+		}
+	})
+
+	t.Run("journal", func(t *testing.T) {
+		if err := d.SetBalanceMode(JournalBalances); err != nil {
+			t.Fatal(err)
+		}
+		for accID := range d.ar.List() {
+			for _, transa := range d.tr.Since(accID, time.Time{}) {
+				v, _ := d.derivedBalance(accID, transa.ID)
+				if b := d.br.TransactionBalance(accID, transa.ID); b == nil || !b.Value.Equal(v) {
+					t.Errorf("journal is not rebuilt, balance of %s after %s: %v, expected %s", accID, transa.Value, b, v)
+				}
+			}
+		}
+	})
+}
+
+func BenchmarkBackdatedTransaction(b *testing.B) {
+	for _, mode := range []int{JournalBalances, DerivedBalances} {
+		l := CreateLedger(CreateAccountRegistry(), CreateBalanceRegistry(), CreateTransactionRegistry(),
+			CreateCurrencyRegistry(), CreateTagRegistry(), CreateTagsMapRegistry())
+		if err := l.SetBalanceMode(mode); err != nil {
+			b.Fatal(err)
+		}
+
+		openedAt := time.Date(2020, time.January, 1, 0, 0, 0, 0, time.UTC)
+		bank, _ := l.CreateAccount("Bank", Asset, "", "USD", openedAt, "1000000000")
+		shop, _ := l.CreateAccount("Shop", Expense, "", "USD", openedAt, "0")
+		for i := range 5000 {
+			if _, err := l.CreateTransaction(bank.ID, shop.ID, openedAt.Add(time.Duration(i+2)*time.Hour), "1", ""); err != nil {
+				b.Fatal(err)
+			}
+		}
+
+		b.Run([]string{"journal", "derived"}[mode], func(b *testing.B) {
+			for i := range b.N {
+				at := openedAt.Add(time.Hour + time.Duration(i)*time.Nanosecond)
+				if _, err := l.CreateTransaction(bank.ID, shop.ID, at, "1", ""); err != nil {
+					b.Fatal(err)
+				}
+				l.AccountBalance(bank.ID)
+			}
+		})
+	}
+}
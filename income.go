@@ -0,0 +1,117 @@
+package miser
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+// IncomeStatement is a profit and loss report: totals of Income and Expense postings
+// over the period and the comparison periods, converted to the currency of report.
+type IncomeStatement struct {
+	Currency string
+	Columns  []ReportColumn // the period, the previous one and the same period last year
+	Income   []ReportLine
+	Expenses []ReportLine
+
+	TotalIncome, TotalExpenses, Net []Amount // per column, Net = Income - Expenses
+}
+
+// Income statement over days from..to (inclusive) in given currency.
+func (l *Ledger) IncomeStatement(from, to time.Time, cur string) (*IncomeStatement, error) {
+	if l.cr.Get(cur) == nil {
+		return nil, fmt.Errorf("currency %q is not supproted", cur)
+	}
+	if to.Before(from) {
+		return nil, errors.New("end of period cannot be before its start")
+	}
+
+	s := &IncomeStatement{Currency: cur, Columns: comparisonColumns(from, to)}
+	n := len(s.Columns)
+
+	var income, expenses []Account
+	values := make(map[ID][]Amount)
+	for _, acc := range l.ar.List() {
+		if acc.Type != Income && acc.Type != Expense {
+			continue
+		}
+
+		v := make([]Amount, n)
+		for i, c := range s.Columns {
+			total, err := l.postingsTotal(&acc, cur, c.From, nextDay(c.To))
+			if err != nil {
+				return nil, err
+			}
+			v[i] = total
+		}
+		values[acc.ID] = v
+
+		if acc.Type == Income {
+			income = append(income, acc)
+		} else {
+			expenses = append(expenses, acc)
+		}
+	}
+
+	s.Income = reportTree(income, values, n)
+	s.Expenses = reportTree(expenses, values, n)
+	s.TotalIncome = reportTotals(s.Income, n)
+	s.TotalExpenses = reportTotals(s.Expenses, n)
+	s.Net = make([]Amount, n)
+	for i := range s.Net {
+		s.Net[i] = s.TotalIncome[i].Sub(s.TotalExpenses[i])
+	}
+	return s, nil
+}
+
+// Total effect of postings of account in [from, end) on its balance, initial balances are skipped,
+// the postings are converted to given currency at their time.
+func (l *Ledger) postingsTotal(acc *Account, cur string, from, end time.Time) (total Amount, err error) {
+	for _, t := range l.tr.Since(acc.ID, from) {
+		if !t.Time.Before(end) {
+			break
+		}
+		if t.IsInitial() {
+			continue
+		}
+
+		operType := Debit
+		if t.Source == acc.ID {
+			operType = Credit
+		}
+		v, err := l.Convert(effect(string(acc.Type), operType, t.Value), string(acc.Cur), cur, t.Time)
+		if err != nil {
+			return Amount{}, err
+		}
+		total = total.Add(v)
+	}
+	return
+}
+
+// Render income statement as text table.
+func (s *IncomeStatement) Render(digits int) string {
+	var sb strings.Builder
+	w := tabwriter.NewWriter(&sb, 0, 0, 2, ' ', 0)
+	rw := reportWriter{w: w, digits: digits}
+
+	rw.header("Income statement, "+s.Currency, s.Columns)
+	rw.lines(s.Income)
+	rw.row("Total income", s.TotalIncome)
+	rw.lines(s.Expenses)
+	rw.row("Total expenses", s.TotalExpenses)
+	rw.row("Net", s.Net)
+
+	w.Flush()
+	return sb.String()
+}
+
+// Income statement over days from..to rendered as text.
+func (l *Ledger) IncomeStatementText(from, to time.Time, cur string) (string, error) {
+	s, err := l.IncomeStatement(from, to, cur)
+	if err != nil {
+		return "", err
+	}
+	return s.Render(l.reportDigits(cur)), nil
+}
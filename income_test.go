@@ -0,0 +1,198 @@
+package miser
+
+import (
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestIncomeStatement(t *testing.T) {
+	t.Parallel()
+
+	// Create repositories:
+	ar := CreateAccountRegistry()
+	tr := CreateTransactionRegistry()
+	br := CreateBalanceRegistry()
+	cr := CreateCurrencyRegistry()
+	tg := CreateTagRegistry()
+	tm := CreateTagsMapRegistry()
+
+	// Create service:
+	l := CreateLedger(ar, br, tr, cr, tg, tm)
+
+	openedAt := time.Date(2023, time.January, 1, 9, 0, 0, 0, time.UTC)
+	l.Rates().Create("EUR", "USD", openedAt, AmountFromInt(2))
+
+	bank, err := l.CreateAccount("Bank", Asset, "checking", "USD", openedAt, "1000")
+	if err != nil {
+		t.Fatal(err)
+	}
+	salary, err := l.CreateAccount("Salary", Income, "", "USD", openedAt, "100000")
+	if err != nil {
+		t.Fatal(err)
+	}
+	groceries, err := l.CreateAccount("Food:Groceries", Expense, "", "USD", openedAt, "0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	cafe, err := l.CreateAccount("Food:Cafe", Expense, "", "USD", openedAt, "0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	euroBank, err := l.CreateAccount("Euro bank", Asset, "", "EUR", openedAt, "1000")
+	if err != nil {
+		t.Fatal(err)
+	}
+	travel, err := l.CreateAccount("Travel", Expense, "", "EUR", openedAt, "0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, d := range []struct {
+		src, dst ID
+		date     time.Time
+		v        string
+	}{
+		{salary.ID, bank.ID, time.Date(2023, time.March, 25, 12, 0, 0, 0, time.UTC), "900"},     // last year
+		{bank.ID, groceries.ID, time.Date(2023, time.March, 26, 12, 0, 0, 0, time.UTC), "80"},   // last year
+		{salary.ID, bank.ID, time.Date(2024, time.February, 25, 12, 0, 0, 0, time.UTC), "1000"}, // previous
+		{bank.ID, cafe.ID, time.Date(2024, time.February, 26, 12, 0, 0, 0, time.UTC), "30"},     // previous
+		{salary.ID, bank.ID, time.Date(2024, time.March, 25, 12, 0, 0, 0, time.UTC), "1000"},
+		{bank.ID, groceries.ID, time.Date(2024, time.March, 1, 0, 0, 0, 0, time.UTC), "100"},
+		{bank.ID, cafe.ID, time.Date(2024, time.March, 31, 23, 0, 0, 0, time.UTC), "50"},
+		{euroBank.ID, travel.ID, time.Date(2024, time.March, 10, 12, 0, 0, 0, time.UTC), "25"},
+		{bank.ID, groceries.ID, time.Date(2024, time.April, 1, 0, 0, 0, 0, time.UTC), "70"}, // next
+	} {
+		if _, err := l.CreateTransaction(d.src, d.dst, d.date, d.v, ""); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	from := time.Date(2024, time.March, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2024, time.March, 31, 0, 0, 0, 0, time.UTC)
+	s, err := l.IncomeStatement(from, to, "USD")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("columns", func(t *testing.T) {
+		want := []ReportColumn{
+			{Name: "Current", From: from, To: to},
+			{Name: "Previous", From: time.Date(2024, time.February, 1, 0, 0, 0, 0, time.UTC),
+				To: time.Date(2024, time.February, 29, 0, 0, 0, 0, time.UTC)},
+			{Name: "Last year", From: time.Date(2023, time.March, 1, 0, 0, 0, 0, time.UTC),
+				To: time.Date(2023, time.March, 31, 0, 0, 0, 0, time.UTC)},
+		}
+		if !slices.Equal(s.Columns, want) {
+			t.Errorf("expected columns %v, got: %v", want, s.Columns)
+		}
+
+		from, to := time.Date(2024, time.March, 5, 0, 0, 0, 0, time.UTC), time.Date(2024, time.March, 14, 0, 0, 0, 0, time.UTC)
+		prevFrom, prevTo := previousPeriod(from, to)
+		if !prevFrom.Equal(time.Date(2024, time.February, 24, 0, 0, 0, 0, time.UTC)) ||
+			!prevTo.Equal(time.Date(2024, time.March, 4, 0, 0, 0, 0, time.UTC)) {
+			t.Errorf("expected previous 10 days, got: %s..%s", prevFrom, prevTo)
+		}
+
+		day := func(y int, m time.Month, d int) time.Time { return time.Date(y, m, d, 0, 0, 0, 0, time.UTC) }
+		for _, c := range []struct {
+			from, to, wantFrom, wantTo time.Time
+		}{
+			{day(2024, time.February, 1), day(2024, time.February, 29), day(2023, time.February, 1), day(2023, time.February, 28)},
+			{day(2025, time.February, 1), day(2025, time.February, 28), day(2024, time.February, 1), day(2024, time.February, 29)},
+			{day(2024, time.February, 10), day(2024, time.February, 29), day(2023, time.February, 10), day(2023, time.February, 28)},
+			{day(2024, time.January, 1), day(2024, time.March, 31), day(2023, time.January, 1), day(2023, time.March, 31)},
+		} {
+			lastFrom, lastTo := lastYearPeriod(c.from, c.to)
+			if !lastFrom.Equal(c.wantFrom) || !lastTo.Equal(c.wantTo) {
+				t.Errorf("expected last year of %s..%s to be %s..%s, got: %s..%s",
+					c.from.Format(time.DateOnly), c.to.Format(time.DateOnly), c.wantFrom.Format(time.DateOnly),
+					c.wantTo.Format(time.DateOnly), lastFrom.Format(time.DateOnly), lastTo.Format(time.DateOnly))
+			}
+		}
+	})
+
+	lineValues := func(lines []ReportLine) map[string][]string {
+		m := make(map[string][]string)
+		for _, line := range lines {
+			for _, v := range line.Values {
+				m[line.Name] = append(m[line.Name], v.String())
+			}
+		}
+		return m
+	}
+
+	t.Run("lines", func(t *testing.T) {
+		expenses := lineValues(s.Expenses)
+		for name, want := range map[string][]string{
+			"Expense:Food":           {"150", "30", "80"},
+			"Expense:Food:Cafe":      {"50", "30", "0"},
+			"Expense:Food:Groceries": {"100", "0", "80"},
+			"Expense:Travel":         {"50", "0", "0"},
+		} {
+			if got := expenses[name]; !slices.Equal(got, want) {
+				t.Errorf("expected %s %v, got: %v", name, want, got)
+			}
+		}
+		if len(expenses) != 4 {
+			t.Errorf("expected 4 lines of expenses, got: %v", expenses)
+		}
+
+		var names []string
+		for _, line := range s.Expenses {
+			names = append(names, line.Name)
+		}
+		if want := []string{"Expense:Food", "Expense:Food:Cafe", "Expense:Food:Groceries", "Expense:Travel"}; !slices.Equal(names, want) {
+			t.Errorf("expected order of lines %v, got: %v", want, names)
+		}
+		if s.Expenses[0].Account != "" || s.Expenses[1].Account != cafe.ID || s.Expenses[1].Depth != 2 {
+			t.Errorf("expected subtree line without account, got: %+v", s.Expenses[:2])
+		}
+
+		if got, want := lineValues(s.Income)["Income:Salary"], []string{"1000", "1000", "900"}; !slices.Equal(got, want) {
+			t.Errorf("expected salary %v, got: %v", want, got)
+		}
+	})
+
+	t.Run("totals", func(t *testing.T) {
+		for _, c := range []struct {
+			name   string
+			values []Amount
+			want   []string
+		}{
+			{"income", s.TotalIncome, []string{"1000", "1000", "900"}},
+			{"expenses", s.TotalExpenses, []string{"200", "30", "80"}},
+			{"net", s.Net, []string{"800", "970", "820"}},
+		} {
+			var got []string
+			for _, v := range c.values {
+				got = append(got, v.String())
+			}
+			if !slices.Equal(got, c.want) {
+				t.Errorf("expected total %s %v, got: %v", c.name, c.want, got)
+			}
+		}
+	})
+
+	t.Run("text", func(t *testing.T) {
+		text, err := l.IncomeStatementText(from, to, "USD")
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, want := range []string{"2024-03-01..2024-03-31", "Groceries", "Total expenses", "800.00"} {
+			if !strings.Contains(text, want) {
+				t.Errorf("expected %q in text:\n%s", want, text)
+			}
+		}
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		if _, err := l.IncomeStatement(from, to, "XXX"); err == nil {
+			t.Error("expected error of unknown currency")
+		}
+		if _, err := l.IncomeStatement(to, from, "USD"); err == nil {
+			t.Error("expected error of wrong period")
+		}
+	})
+}
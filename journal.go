@@ -9,19 +9,27 @@ import (
 )
 
 const (
-	ACCOUNTS_FILE     = "miser.ar"
-	TRANSACTIONS_FILE = "miser.tr"
-	BALANCE_FILE      = "miser.br"
-	TAGS_FILE         = "miser.tg"
-	TAGS_MAPPING_FILE = "miser.tm"
+	ACCOUNTS_FILE        = "miser.ar"
+	TRANSACTIONS_FILE    = "miser.tr"
+	BALANCE_FILE         = "miser.br"
+	TAGS_FILE            = "miser.tg"
+	TAGS_MAPPING_FILE    = "miser.tm"
+	RATES_FILE           = "miser.rt"
+	COMMODITIES_FILE     = "miser.cm"
+	RECONCILIATIONS_FILE = "miser.rc"
+	SCHEDULES_FILE       = "miser.sc"
+	SAVED_QUERIES_FILE   = "miser.sq"
+	ASSERTIONS_FILE      = "miser.as"
 )
 
 type Entities interface {
-	Account | Transaction | Balance | Tag | TagMap
+	Account | Transaction | Balance | Tag | TagMap | Rate | Commodity | Reconciliation | Schedule | SavedQuery | Assertion
 }
 
 type Registry[E Entities] interface {
-	*AccountRegistry | *TransactionRegistry | *BalanceRegistry | *TagRegistry | *TagMapRegistry
+	*AccountRegistry | *TransactionRegistry | *BalanceRegistry | *TagRegistry | *TagMapRegistry |
+		*RateRegistry | *CurrencyRegistry | *ReconciliationRegistry |
+		*ScheduleRegistry | *SavedQueryRegistry | *AssertionRegistry
 
 	Add(e E) int
 	SyncQueued() []E
@@ -0,0 +1,157 @@
+package miser
+
+import (
+	"cmp"
+	"encoding/base64"
+	"errors"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var ErrCursor = errors.New("malformed query cursor")
+
+// Sort orders of query results.
+const (
+	ByTime = iota
+	ByAmount
+)
+
+// Modes of deleted transactions filter.
+const (
+	ExcludeDeleted = iota
+	IncludeDeleted
+	OnlyDeleted
+)
+
+// Query describes filters, order and page of transactions, the zero value
+// of any filter means no filtering, lists of values match any of them, e.g.:
+//
+//	Query{Accounts: []ID{bank}, From: jan, To: feb, Tags: []string{Periodic}} // periodic payments of January
+//	Query{Sort: ByAmount, Desc: true, Limit: 10}                             // top 10 transactions
+type Query struct {
+	Accounts []ID      // source or destination account
+	From, To time.Time // period [From, To)
+	Min, Max *Amount   // value range, inclusive
+	States   []int     // Uncleared, Pending, Cleared
+	Tags     []string  // tag names
+	Deleted  int       // one of: ExcludeDeleted, IncludeDeleted, OnlyDeleted
+	Text     string    // case insensitive substring of text
+
+	// custom filter, applied after all others:
+	Where func(*Transaction) bool
+
+	Sort   int    // ByTime (ties by sequence) or ByAmount (ties by ID)
+	Desc   bool   // descending order
+	Limit  int    // page size, 0 - all
+	Cursor string // position after which the page starts, see Page.Next
+}
+
+// Page is a part of query results.
+type Page struct {
+	Items []Transaction
+	Next  string // cursor of the next page, empty for the last one
+}
+
+// Check if transaction meets filters of query.
+func (q *Query) match(l *Ledger, t *Transaction) bool {
+	switch {
+	case q.Deleted == ExcludeDeleted && t.Deleted, q.Deleted == OnlyDeleted && !t.Deleted:
+		return false
+	case len(q.Accounts) > 0 && !slices.Contains(q.Accounts, t.Source) && !slices.Contains(q.Accounts, t.Dest):
+		return false
+	case !q.From.IsZero() && t.Time.Before(q.From), !q.To.IsZero() && !t.Time.Before(q.To):
+		return false
+	case q.Min != nil && t.Value.Cmp(*q.Min) < 0, q.Max != nil && t.Value.Cmp(*q.Max) > 0:
+		return false
+	case len(q.States) > 0 && !slices.Contains(q.States, t.State):
+		return false
+	case q.Text != "" && !strings.Contains(strings.ToLower(string(t.Text)), strings.ToLower(q.Text)):
+		return false
+	case len(q.Tags) > 0 && !slices.ContainsFunc(q.Tags, func(n string) bool {
+		tag := l.tg.GetByName(n)
+		return tag != nil && l.tm.Has(tag.ID, t.ID)
+	}):
+		return false
+	case q.Where != nil && !q.Where(t):
+		return false
+	}
+	return true
+}
+
+// Compare transactions in the order of query.
+func (q *Query) compare(a, b Transaction) int {
+	c := compareTransactions(a, b)
+	if q.Sort == ByAmount {
+		if c = a.Value.Cmp(b.Value); c == 0 {
+			c = cmp.Compare(a.ID, b.ID)
+		}
+	}
+	if q.Desc {
+		return -c
+	}
+	return c
+}
+
+// Cursor is the sort key of the last transaction of page, so the next page
+// is stable regardless of transactions added or deleted in between.
+func (q *Query) cursor(t Transaction) string {
+	key := t.Time.Format(time.RFC3339Nano) + "/" + strconv.FormatUint(t.Seq, 10)
+	if q.Sort == ByAmount {
+		key = t.Value.String()
+	}
+	return base64.RawURLEncoding.EncodeToString([]byte(key + " " + string(t.ID)))
+}
+
+// Decode cursor to the transaction carrying its sort key.
+func (q *Query) position() (t Transaction, err error) {
+	b, err := base64.RawURLEncoding.DecodeString(q.Cursor)
+	if err != nil {
+		return t, ErrCursor
+	}
+
+	key, id, ok := strings.Cut(string(b), " ")
+	if !ok {
+		return t, ErrCursor
+	}
+	t.ID = ID(id)
+
+	if q.Sort == ByAmount {
+		t.Value, err = ParseAmount(key, AmountDigits)
+	} else if ts, seq, ok := strings.Cut(key, "/"); !ok {
+		err = ErrCursor
+	} else if t.Time, err = time.Parse(time.RFC3339Nano, ts); err == nil {
+		t.Seq, err = strconv.ParseUint(seq, 10, 64)
+	}
+	if err != nil {
+		return t, ErrCursor
+	}
+	return
+}
+
+// Query transactions: filter, sort and return a page of them.
+// Amounts of different currencies are compared by their numeric values.
+func (l *Ledger) Query(q Query) (page Page, err error) {
+	var after *Transaction
+	if q.Cursor != "" {
+		t, err := q.position()
+		if err != nil {
+			return page, err
+		}
+		after = &t
+	}
+
+	for _, t := range l.tr.List() {
+		if (after == nil || q.compare(t, *after) > 0) && q.match(l, &t) {
+			page.Items = append(page.Items, t)
+		}
+	}
+	slices.SortFunc(page.Items, q.compare)
+
+	if q.Limit > 0 && len(page.Items) > q.Limit {
+		page.Items = page.Items[:q.Limit]
+		page.Next = q.cursor(page.Items[q.Limit-1])
+	}
+	return
+}
@@ -0,0 +1,124 @@
+package miser
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestQuery(t *testing.T) {
+	t.Parallel()
+
+	// Create repositories:
+	ar := CreateAccountRegistry()
+	tr := CreateTransactionRegistry()
+	br := CreateBalanceRegistry()
+	cr := CreateCurrencyRegistry()
+	tg := CreateTagRegistry()
+	tm := CreateTagsMapRegistry()
+
+	// Create service:
+	l := CreateLedger(ar, br, tr, cr, tg, tm)
+
+	openedAt := time.Date(2024, time.January, 1, 9, 0, 0, 0, time.UTC)
+	bank, err := l.CreateAccount("Bank", Asset, "checking", "USD", openedAt, "1000")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	shop, err := l.CreateAccount("Shop", Expense, "groceries", "USD", openedAt, "0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cafe, err := l.CreateAccount("Cafe", Expense, "", "USD", openedAt, "0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var trs []*Transaction
+	for i, v := range []string{"30", "10", "50", "20", "40"} {
+		dst, txt := shop.ID, "Milk and bread"
+		if i%2 == 1 {
+			dst, txt = cafe.ID, "Coffee"
+		}
+		transa, err := l.CreateTransaction(bank.ID, dst, openedAt.AddDate(0, 0, i+1), v, txt)
+		if err != nil {
+			t.Fatal(err)
+		}
+		trs = append(trs, transa)
+	}
+	l.tagItem("Food", trs[1].ID)
+	if err := l.DeleteTransaction(trs[4].ID); err != nil {
+		t.Fatal(err)
+	}
+
+	min, max := MustParseAmount("15"), MustParseAmount("45")
+	regular := func(t *Transaction) bool { return !t.IsInitial() }
+
+	tests := []struct {
+		name  string
+		query Query
+		want  []*Transaction
+	}{
+		{"account", Query{Accounts: []ID{cafe.ID}, Where: regular}, []*Transaction{trs[1], trs[3]}},
+		{"period", Query{From: trs[1].Time, To: trs[3].Time}, []*Transaction{trs[1], trs[2]}},
+		{"amount", Query{Accounts: []ID{shop.ID, cafe.ID}, Min: &min, Max: &max}, []*Transaction{trs[0], trs[3]}},
+		{"text", Query{Text: "coffee"}, []*Transaction{trs[1], trs[3]}},
+		{"tag", Query{Tags: []string{"Food"}}, []*Transaction{trs[1]}},
+		{"deleted", Query{Deleted: OnlyDeleted}, []*Transaction{trs[4]}},
+		{"where", Query{Where: func(t *Transaction) bool { return t.Dest == shop.ID && !t.IsInitial() }}, []*Transaction{trs[0], trs[2]}},
+		{"by amount", Query{Accounts: []ID{shop.ID, cafe.ID}, Where: regular, Sort: ByAmount, Desc: true}, []*Transaction{trs[2], trs[0], trs[3], trs[1]}},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			page, err := l.Query(tc.query)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(page.Items) != len(tc.want) {
+				t.Fatalf("expected %d transactions, got: %d", len(tc.want), len(page.Items))
+			}
+			for i, w := range tc.want {
+				if page.Items[i].ID != w.ID {
+					t.Errorf("expected %s at %d, got: %s", w.Value, i, page.Items[i].Value)
+				}
+			}
+		})
+	}
+
+	t.Run("pagination", func(t *testing.T) {
+		q := Query{Accounts: []ID{shop.ID, cafe.ID}, Where: regular, Sort: ByAmount, Limit: 3}
+
+		var got []ID
+		for {
+			page, err := l.Query(q)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, t := range page.Items {
+				got = append(got, t.ID)
+			}
+			if page.Next == "" {
+				break
+			}
+			q.Cursor = page.Next
+		}
+
+		want := []ID{trs[1].ID, trs[3].ID, trs[0].ID, trs[2].ID}
+		if len(got) != len(want) {
+			t.Fatalf("expected %d transactions, got: %d", len(want), len(got))
+		}
+		for i := range want {
+			if got[i] != want[i] {
+				t.Errorf("unexpected order of pages: %v", got)
+				break
+			}
+		}
+
+		if _, err := l.Query(Query{Cursor: "garbage"}); !errors.Is(err, ErrCursor) {
+			t.Errorf("expected cursor error, got: %v", err)
+		}
+	})
+}
@@ -0,0 +1,317 @@
+package miser
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// QuerySyntaxError is an error of query string at given position.
+type QuerySyntaxError struct {
+	Pos int // position of the error (1-based byte offset)
+	Msg string
+}
+
+func (e *QuerySyntaxError) Error() string {
+	return fmt.Sprintf("query syntax error at position %d: %s", e.Pos, e.Msg)
+}
+
+func syntaxError(pos int, format string, a ...any) error {
+	return &QuerySyntaxError{Pos: pos + 1, Msg: fmt.Sprintf(format, a...)}
+}
+
+// Word of query string and its position, quotes are removed.
+type queryWord struct {
+	pos  int
+	text string
+}
+
+// Split query string to words by whitespaces, values could be quoted
+// or be a regular expression which may contain whitespaces.
+func splitQuery(s string) (words []queryWord, err error) {
+	for i := 0; i < len(s); {
+		if s[i] == ' ' || s[i] == '\t' {
+			i++
+			continue
+		}
+
+		w := queryWord{pos: i}
+		for i < len(s) && s[i] != ' ' && s[i] != '\t' {
+			switch c := s[i]; {
+			case c == '"' || c == '\'':
+				j := strings.IndexByte(s[i+1:], c)
+				if j < 0 {
+					return nil, syntaxError(i, "unterminated quote")
+				}
+				w.text += s[i+1 : i+1+j]
+				i += j + 2
+			case c == '/' && i > 0 && s[i-1] == ':':
+				j := strings.IndexByte(s[i+1:], '/')
+				if j < 0 {
+					return nil, syntaxError(i, "unterminated regular expression")
+				}
+				w.text += s[i : i+j+2]
+				i += j + 2
+			default:
+				w.text += s[i : i+1] // raw byte, multi-byte characters are kept intact
+				i++
+			}
+		}
+		words = append(words, w)
+	}
+	return
+}
+
+// Term of query: a filter of transaction by one field.
+type queryTerm struct {
+	field string
+	not   bool
+	match func(*Transaction) bool
+}
+
+// Parse query string into query of transactions, its filters are set to Where,
+// so sorting and pagination could be added. Syntax is similar to hledger one:
+//
+//	acct:Expense:Food   account or its subtree (by full name), acct:/regexp/i
+//	date:2024-03        period: year, month or day, or range date:2024-01..2024-03 (end exclusive)
+//	amt:>5000           value: >, >=, <, <=, = or just a number
+//	tag:Periodic        tag name
+//	desc:aeon           substring of text, desc:/regexp/i; bare words are also matched against text
+//	state:cleared       cleared, pending or uncleared
+//	not:state:cleared   negation of any term
+//
+// Terms of the same field are OR-ed, terms of different fields and negated terms are AND-ed.
+func (l *Ledger) ParseQuery(s string) (Query, error) {
+	words, err := splitQuery(s)
+	if err != nil {
+		return Query{}, err
+	}
+
+	var terms []queryTerm
+	for _, w := range words {
+		t, err := l.parseTerm(w)
+		if err != nil {
+			return Query{}, err
+		}
+		terms = append(terms, t)
+	}
+
+	where := func(t *Transaction) bool {
+		fields := make(map[string]bool) // whether some term of field matched
+		for _, term := range terms {
+			matched := term.match(t)
+			if term.not {
+				if matched {
+					return false
+				}
+				continue
+			}
+			fields[term.field] = fields[term.field] || matched
+		}
+		for _, matched := range fields {
+			if !matched {
+				return false
+			}
+		}
+		return true
+	}
+	return Query{Where: where}, nil
+}
+
+func (l *Ledger) parseTerm(w queryWord) (term queryTerm, err error) {
+	pos, text := w.pos, w.text
+	for strings.HasPrefix(text, "not:") {
+		term.not = !term.not
+		pos, text = pos+4, text[4:]
+	}
+
+	start := pos
+	field, value, ok := strings.Cut(text, ":")
+	if !ok {
+		field, value = "desc", text
+	} else {
+		pos += len(field) + 1
+	}
+	term.field = field
+
+	if value == "" {
+		return term, syntaxError(pos, "empty value of %s", field)
+	}
+
+	switch field {
+	case "acct":
+		term.match, err = l.accountMatcher(pos, value)
+	case "date":
+		term.match, err = dateMatcher(pos, value)
+	case "amt":
+		term.match, err = amountMatcher(pos, value)
+	case "tag":
+		term.match = l.tagMatcher(value)
+	case "desc":
+		term.match, err = textMatcher(pos, value)
+	case "state":
+		term.match, err = stateMatcher(pos, value)
+	default:
+		err = syntaxError(start, "unknown field %q", field)
+	}
+	return
+}
+
+// Compile /regexp/flags value, only i (case insensitive) flag is supported.
+func parseRegexp(pos int, value string) (*regexp.Regexp, error) {
+	j := strings.LastIndexByte(value, '/')
+	if j == 0 {
+		return nil, syntaxError(pos, "unterminated regular expression")
+	}
+	pattern, flags := value[1:j], value[j+1:]
+
+	switch flags {
+	case "":
+	case "i":
+		pattern = "(?i)" + pattern
+	default:
+		return nil, syntaxError(pos+j+1, "unknown flags of regular expression: %q", flags)
+	}
+
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, syntaxError(pos+1, "%s", err)
+	}
+	return re, nil
+}
+
+func isRegexp(value string) bool { return len(value) > 1 && value[0] == '/' }
+
+func (l *Ledger) accountMatcher(pos int, value string) (func(*Transaction) bool, error) {
+	match := func(acc *Account) bool { return acc.Under(value) }
+	if isRegexp(value) {
+		re, err := parseRegexp(pos, value)
+		if err != nil {
+			return nil, err
+		}
+		match = func(acc *Account) bool { return re.MatchString(acc.FullName()) }
+	}
+
+	return func(t *Transaction) bool {
+		for _, accID := range uniqueIDs(t.Source, t.Dest) {
+			if acc := l.ar.Get(accID); acc != nil && match(acc) {
+				return true
+			}
+		}
+		return false
+	}, nil
+}
+
+// Parse period of date: year, month or day, [from, to).
+func parsePeriod(pos int, value string) (from, to time.Time, err error) {
+	layouts := []struct {
+		layout       string
+		years, month int
+	}{{"2006", 1, 0}, {"2006-01", 0, 1}, {"2006-01-02", 0, 0}}
+
+	for _, p := range layouts {
+		if len(value) != len(p.layout) {
+			continue
+		}
+		if from, err = time.Parse(p.layout, value); err != nil {
+			break
+		}
+		if p.years == 0 && p.month == 0 {
+			return from, from.AddDate(0, 0, 1), nil
+		}
+		return from, from.AddDate(p.years, p.month, 0), nil
+	}
+	return from, to, syntaxError(pos, "wrong date %q, expected YYYY, YYYY-MM or YYYY-MM-DD", value)
+}
+
+func dateMatcher(pos int, value string) (func(*Transaction) bool, error) {
+	var from, to time.Time
+	var err error
+
+	if a, b, ok := strings.Cut(value, ".."); ok {
+		if a != "" {
+			if from, _, err = parsePeriod(pos, a); err != nil {
+				return nil, err
+			}
+		}
+		if b != "" {
+			if to, _, err = parsePeriod(pos+len(a)+2, b); err != nil {
+				return nil, err
+			}
+		}
+	} else if from, to, err = parsePeriod(pos, value); err != nil {
+		return nil, err
+	}
+
+	return func(t *Transaction) bool {
+		// compare calendar dates of transaction regardless of its location:
+		y, m, d := t.Time.Date()
+		date := time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+		return (from.IsZero() || !date.Before(from)) && (to.IsZero() || date.Before(to))
+	}, nil
+}
+
+func amountMatcher(pos int, value string) (func(*Transaction) bool, error) {
+	var op string
+	for _, o := range []string{">=", "<=", ">", "<", "="} {
+		if strings.HasPrefix(value, o) {
+			op = o
+			break
+		}
+	}
+
+	v, err := ParseAmount(value[len(op):], AmountDigits)
+	if err != nil {
+		return nil, syntaxError(pos+len(op), "wrong amount %q", value[len(op):])
+	}
+
+	return func(t *Transaction) bool {
+		c := t.Value.Cmp(v)
+		switch op {
+		case ">=":
+			return c >= 0
+		case "<=":
+			return c <= 0
+		case ">":
+			return c > 0
+		case "<":
+			return c < 0
+		}
+		return c == 0
+	}, nil
+}
+
+func (l *Ledger) tagMatcher(name string) func(*Transaction) bool {
+	return func(t *Transaction) bool {
+		for _, tagID := range l.tm.Tags(t.ID) {
+			tag := l.tg.GetById(tagID)
+			if tag != nil && strings.EqualFold(string(tag.Name), name) && l.tm.Has(tagID, t.ID) {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+func textMatcher(pos int, value string) (func(*Transaction) bool, error) {
+	if isRegexp(value) {
+		re, err := parseRegexp(pos, value)
+		if err != nil {
+			return nil, err
+		}
+		return func(t *Transaction) bool { return re.MatchString(string(t.Text)) }, nil
+	}
+
+	value = strings.ToLower(value)
+	return func(t *Transaction) bool { return strings.Contains(strings.ToLower(string(t.Text)), value) }, nil
+}
+
+func stateMatcher(pos int, value string) (func(*Transaction) bool, error) {
+	states := map[string]int{"uncleared": Uncleared, "pending": Pending, "cleared": Cleared}
+	state, ok := states[strings.ToLower(value)]
+	if !ok {
+		return nil, syntaxError(pos, "unknown state %q, expected cleared, pending or uncleared", value)
+	}
+	return func(t *Transaction) bool { return t.State == state }, nil
+}
@@ -0,0 +1,172 @@
+package miser
+
+import (
+	"errors"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestParseQuery(t *testing.T) {
+	t.Parallel()
+
+	// Create repositories:
+	ar := CreateAccountRegistry()
+	tr := CreateTransactionRegistry()
+	br := CreateBalanceRegistry()
+	cr := CreateCurrencyRegistry()
+	tg := CreateTagRegistry()
+	tm := CreateTagsMapRegistry()
+
+	// Create service:
+	l := CreateLedger(ar, br, tr, cr, tg, tm)
+
+	openedAt := time.Date(2024, time.January, 1, 9, 0, 0, 0, time.UTC)
+	bank, err := l.CreateAccount("Bank", Asset, "checking", "JPY", openedAt, "1000000")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var expenses []*Account
+	for _, n := range []string{"Food:Groceries", "Food:Cafe", "Rent", "Foodstuff", "食品:卵"} {
+		acc, err := l.CreateAccount(n, Expense, "", "JPY", openedAt, "0")
+		if err != nil {
+			t.Fatal(err)
+		}
+		expenses = append(expenses, acc)
+	}
+
+	data := []struct {
+		acc  int
+		date time.Time
+		v    string
+		txt  string
+	}{
+		{0, time.Date(2024, time.February, 10, 12, 0, 0, 0, time.UTC), "4000", "AEON Supermarket"},
+		{1, time.Date(2024, time.March, 1, 12, 0, 0, 0, time.UTC), "800", "Doutor coffee"},
+		{2, time.Date(2024, time.March, 25, 12, 0, 0, 0, time.UTC), "90000", "Rent of March"},
+		{0, time.Date(2024, time.March, 31, 23, 0, 0, 0, time.UTC), "6000", "aeon mall"},
+		{3, time.Date(2024, time.April, 2, 12, 0, 0, 0, time.UTC), "300", "Market"},
+		{4, time.Date(2024, time.January, 15, 12, 0, 0, 0, time.UTC), "1500", "卵2kgと小麦粉"},
+	}
+
+	var trs []ID
+	for _, d := range data {
+		transa, err := l.CreateTransaction(bank.ID, expenses[d.acc].ID, d.date, d.v, d.txt)
+		if err != nil {
+			t.Fatal(err)
+		}
+		trs = append(trs, transa.ID)
+	}
+	l.tagItem(Periodic, trs[2])
+	l.setState(l.tr.Get(trs[0]), Cleared)
+
+	tests := []struct {
+		query string
+		want  []int
+	}{
+		{"acct:Expense:Food", []int{0, 1, 3}},
+		{"acct:expense:food:cafe", []int{1}},
+		{"acct:/^Expense:Food/", []int{0, 1, 3, 4}},
+		{"date:2024-03", []int{1, 2, 3}},
+		{"date:2024-02..2024-03-02", []int{0, 1}},
+		{"date:2024-04.. acct:Expense", []int{4}},
+		{"amt:>5000 acct:Expense", []int{2, 3}},
+		{"amt:<=800 amt:=4000 acct:Expense", []int{0, 1, 4}},
+		{"tag:periodic", []int{2}},
+		{"desc:/aeon/i", []int{0, 3}},
+		{"desc:'coffee' desc:market", []int{0, 1, 4}},
+		{"rent", []int{2}},
+		{"acct:Expense:Food desc:/aeon/i not:state:cleared", []int{3}},
+		{`desc:"aeon mall"`, []int{3}},
+		{"not:not:state:cleared", []int{0}},
+		{"desc:卵", []int{5}},
+		{"小麦粉", []int{5}},
+		{`desc:"卵2kgと"`, []int{5}},
+		{"acct:Expense:食品", []int{5}},
+		{"acct:Expense:食品:卵 desc:/小麦/", []int{5}},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.query, func(t *testing.T) {
+			q, err := l.ParseQuery(tc.query)
+			if err != nil {
+				t.Fatal(err)
+			}
+			page, err := l.Query(q)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var got []int
+			for _, transa := range page.Items {
+				if !transa.IsInitial() {
+					got = append(got, slices.Index(trs, transa.ID))
+				}
+			}
+			if !slices.Equal(got, tc.want) {
+				t.Errorf("expected transactions %v, got: %v", tc.want, got)
+			}
+		})
+	}
+
+	errorTests := []struct {
+		query string
+		pos   int
+	}{
+		{"acct:Expense foo:bar", 14},
+		{"date:2024-3", 6},
+		{"date:2024-01..march", 15},
+		{"amt:>abc", 6},
+		{"not:state:done", 11},
+		{`desc:"aeon`, 6},
+		{"desc:/aeon", 6},
+		{"desc:/aeon/x", 12},
+		{"desc:/(/", 7},
+		{"acct:", 6},
+	}
+
+	for _, tc := range errorTests {
+		t.Run(tc.query, func(t *testing.T) {
+			var serr *QuerySyntaxError
+			if _, err := l.ParseQuery(tc.query); !errors.As(err, &serr) {
+				t.Fatalf("expected syntax error, got: %v", err)
+			}
+			if serr.Pos != tc.pos {
+				t.Errorf("expected error at position %d, got: %s", tc.pos, serr)
+			}
+		})
+	}
+
+	t.Run("saved", func(t *testing.T) {
+		if _, err := l.SaveQuery("food", "acct:Expense:Food date:"); err == nil {
+			t.Error("error expected for wrong query, nil found")
+		}
+
+		if _, err := l.SaveQuery("food", "acct:Expense:Food"); err != nil {
+			t.Fatal(err)
+		}
+		saved, err := l.SaveQuery("food", "acct:Expense:Food date:2024-03")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if n := len(l.Queries().List()); n != 1 {
+			t.Errorf("expected the query to be replaced, got %d queries", n)
+		}
+
+		q, err := l.NamedQuery("food")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if page, _ := l.Query(q); len(page.Items) != 2 {
+			t.Errorf("expected 2 transactions of saved query %q, got: %d", saved.Text, len(page.Items))
+		}
+
+		if err := l.DeleteQuery("food"); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := l.NamedQuery("food"); err == nil {
+			t.Error("error expected for deleted query, nil found")
+		}
+	})
+}
@@ -0,0 +1,268 @@
+package miser
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"os"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Rate is a price of one unit of Base currency in Quote currency at given date,
+// e.g. Base: EUR, Quote: USD, Value: 1.0890.
+// Like the balance it is a value object, the last version of pair rate at date wins.
+type Rate struct {
+	Base, Quote EncryptedString
+	Date        time.Time
+	Value       Amount
+	Deleted     bool
+}
+
+func (r Rate) Key() string {
+	return fmt.Sprintf("%s-%s-%s", r.Base, r.Quote, r.Date.Format(time.DateOnly))
+}
+
+func (r Rate) pair() string { return rateKey(string(r.Base), string(r.Quote)) }
+
+func rateKey(base, quote string) string { return base + "-" + quote }
+
+// Rates are stored per day, time of day and location are dropped.
+func rateDate(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+type RateRegistry struct {
+	items  map[string]Rate
+	queued map[string]Rate
+
+	// index of rates of currency pair sorted by date, used for lookup of nearest rate
+	pairs      map[string][]Rate
+	currencies map[string]struct{}
+
+	sync.RWMutex
+}
+
+func (rr *RateRegistry) List() (rates []Rate) {
+	rr.RLock()
+	defer rr.RUnlock()
+	for _, v := range rr.items {
+		rates = append(rates, v)
+	}
+	return
+}
+
+func (rr *RateRegistry) Add(r Rate) int {
+	rr.Lock()
+	defer rr.Unlock()
+
+	r.Date = rateDate(r.Date)
+	rr.items[r.Key()] = r
+
+	p := r.pair()
+	rates := rr.pairs[p]
+	i, found := slices.BinarySearchFunc(rates, r.Date, func(e Rate, t time.Time) int { return e.Date.Compare(t) })
+	switch {
+	case r.Deleted && found:
+		rates = slices.Delete(rates, i, i+1)
+	case r.Deleted:
+	case found:
+		rates[i] = r
+	default:
+		rates = slices.Insert(rates, i, r)
+	}
+	rr.pairs[p] = rates
+
+	rr.currencies[string(r.Base)] = struct{}{}
+	rr.currencies[string(r.Quote)] = struct{}{}
+	return 1
+}
+
+func (rr *RateRegistry) AddQueued(r Rate) {
+	rr.Lock()
+	defer rr.Unlock()
+	r.Date = rateDate(r.Date)
+	rr.queued[r.Key()] = r
+}
+
+func (rr *RateRegistry) SyncQueued() (changes []Rate) {
+	rr.RLock()
+	defer rr.RUnlock()
+	for _, v := range rr.queued {
+		changes = append(changes, v)
+	}
+	return
+}
+
+// Create a new rate of currency pair at given date (or a new version of existing one).
+func (rr *RateRegistry) Create(base, quote string, date time.Time, v Amount) *Rate {
+	r := Rate{Base: EncryptedString(base), Quote: EncryptedString(quote), Date: rateDate(date), Value: v}
+	rr.Add(r)
+	rr.AddQueued(r)
+	return &r
+}
+
+// Find the nearest rate of currency pair on or before given date.
+func (rr *RateRegistry) Get(base, quote string, date time.Time) *Rate {
+	rr.RLock()
+	defer rr.RUnlock()
+	return rr.nearest(base, quote, date)
+}
+
+func (rr *RateRegistry) nearest(base, quote string, date time.Time) *Rate {
+	rates := rr.pairs[rateKey(base, quote)]
+	date = rateDate(date)
+	i, found := slices.BinarySearchFunc(rates, date, func(e Rate, t time.Time) int { return e.Date.Compare(t) })
+	if !found {
+		if i == 0 {
+			return nil
+		}
+		i--
+	}
+	r := rates[i]
+	return &r
+}
+
+// Find direct or inverse rate of currency pair.
+func (rr *RateRegistry) quote(from, to string, date time.Time) (*big.Rat, bool) {
+	if r := rr.nearest(from, to, date); r != nil && r.Value.Sign() > 0 {
+		return r.Value.Rat(), true
+	}
+	if r := rr.nearest(to, from, date); r != nil && r.Value.Sign() > 0 {
+		return new(big.Rat).Inv(r.Value.Rat()), true
+	}
+	return nil, false
+}
+
+// Find exact rate of currency pair: directly, as inverse of opposite pair or
+// as cross rate via a common currency, e.g. USD/JPY via EUR/USD and EUR/JPY
+// of the ECB reference rates.
+func (rr *RateRegistry) exact(from, to string, date time.Time) (*big.Rat, error) {
+	if from == to {
+		return big.NewRat(1, 1), nil
+	}
+
+	rr.RLock()
+	defer rr.RUnlock()
+
+	if v, ok := rr.quote(from, to, date); ok {
+		return v, nil
+	}
+
+	var codes []string
+	for c := range rr.currencies {
+		if c != from && c != to {
+			codes = append(codes, c)
+		}
+	}
+	slices.Sort(codes)
+
+	for _, c := range codes {
+		v1, ok := rr.quote(from, c, date)
+		if !ok {
+			continue
+		}
+		v2, ok := rr.quote(c, to, date)
+		if !ok {
+			continue
+		}
+		return v1.Mul(v1, v2), nil
+	}
+	return nil, fmt.Errorf("rate %s/%s not found on %s", from, to, date.Format(time.DateOnly))
+}
+
+// Rate of currency pair at given date.
+func (rr *RateRegistry) Rate(from, to string, date time.Time) (Amount, error) {
+	rate, err := rr.exact(from, to, date)
+	if err != nil {
+		return Amount{}, err
+	}
+	return amountFromRat(rate), nil
+}
+
+// Convert value of one currency to another using the rate known at date.
+func (rr *RateRegistry) Convert(v Amount, from, to string, date time.Time) (Amount, error) {
+	rate, err := rr.exact(from, to, date)
+	if err != nil {
+		return Amount{}, err
+	}
+	return v.Mul(rate), nil
+}
+
+// Import rates from a local CSV file of ECB format (eurofxref-hist.csv):
+//
+//	Date,USD,JPY,...
+//	2024-03-15,1.0890,161.23,...
+//
+// every value is a price of one unit of base currency (EUR for ECB),
+// missing values ("N/A" or blank) are skipped.
+func (rr *RateRegistry) ImportCSV(fpath, base string) (n int, err error) {
+	f, err := os.Open(fpath)
+	if err != nil {
+		return n, err
+	}
+
+	defer func() {
+		if e := f.Close(); e != nil {
+			if err != nil {
+				err = errors.Join(e, err)
+			} else {
+				err = e
+			}
+		}
+	}()
+
+	r := csv.NewReader(f)
+	r.FieldsPerRecord = -1
+	r.TrimLeadingSpace = true
+
+	header, err := r.Read()
+	if err != nil {
+		return n, err
+	}
+
+	for {
+		rec, err := r.Read()
+		if err != nil {
+			if err == io.EOF {
+				break
+			}
+			return n, err
+		}
+
+		date, err := time.Parse(time.DateOnly, strings.TrimSpace(rec[0]))
+		if err != nil {
+			return n, err
+		}
+
+		for i := 1; i < len(rec) && i < len(header); i++ {
+			quote, s := strings.TrimSpace(header[i]), strings.TrimSpace(rec[i])
+			if quote == "" || s == "" || s == "N/A" {
+				continue
+			}
+			v, err := ParseAmount(s, AmountDigits)
+			if err != nil {
+				return n, fmt.Errorf("line %s, currency %s: %w", rec[0], quote, err)
+			}
+			rr.Create(base, quote, date, v)
+			n++
+		}
+	}
+	return n, nil
+}
+
+func CreateRateRegistry() *RateRegistry {
+	return &RateRegistry{
+		items:      make(map[string]Rate),
+		queued:     make(map[string]Rate),
+		pairs:      make(map[string][]Rate),
+		currencies: make(map[string]struct{}),
+	}
+}
+
+func (rr *RateRegistry) Load() (int, error) { return Load(rr, RATES_FILE) }
+func (rr *RateRegistry) Save() (int, error) { return Save(rr, RATES_FILE) }
@@ -0,0 +1,162 @@
+package miser
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestRateNearest(t *testing.T) {
+	t.Parallel()
+
+	rr := CreateRateRegistry()
+	d1 := time.Date(2024, time.March, 14, 0, 0, 0, 0, time.UTC)
+	d2 := time.Date(2024, time.March, 18, 0, 0, 0, 0, time.UTC)
+	rr.Create("EUR", "USD", d1, MustParseAmount("1.088"))
+	rr.Create("EUR", "USD", d2, MustParseAmount("1.09"))
+
+	if r := rr.Get("EUR", "USD", d1.Add(-time.Hour)); r != nil {
+		t.Errorf("expected no rate before the first one, got: %#v", r)
+	}
+
+	cases := []struct {
+		date time.Time
+		want string
+	}{
+		{d1, "1.088"},
+		{d1.Add(15 * time.Hour), "1.088"},
+		{time.Date(2024, time.March, 16, 0, 0, 0, 0, time.UTC), "1.088"},
+		{d2, "1.09"},
+		{d2.AddDate(1, 0, 0), "1.09"},
+	}
+	for _, c := range cases {
+		r := rr.Get("EUR", "USD", c.date)
+		if r == nil {
+			t.Fatalf("rate not found at %s", c.date)
+		}
+		if r.Value.String() != c.want {
+			t.Errorf("expected rate %s at %s, got: %s", c.want, c.date, r.Value)
+		}
+	}
+
+	// a new version of the rate at the same date replaces the old one:
+	rr.Create("EUR", "USD", d2, MustParseAmount("1.091"))
+	if r := rr.Get("EUR", "USD", d2); r == nil || r.Value.String() != "1.091" {
+		t.Errorf("expected the last version of rate, got: %#v", r)
+	}
+}
+
+func TestRateCross(t *testing.T) {
+	t.Parallel()
+
+	rr := CreateRateRegistry()
+	d := time.Date(2024, time.March, 15, 0, 0, 0, 0, time.UTC)
+	rr.Create("EUR", "USD", d, AmountFromInt(2))
+	rr.Create("EUR", "JPY", d, AmountFromInt(300))
+
+	t.Run("inverse", func(t *testing.T) {
+		v, err := rr.Rate("USD", "EUR", d)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if v.String() != "0.5" {
+			t.Errorf("expected 0.5, got: %s", v)
+		}
+	})
+
+	t.Run("cross", func(t *testing.T) {
+		v, err := rr.Rate("USD", "JPY", d)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if v.String() != "150" {
+			t.Errorf("expected 150, got: %s", v)
+		}
+	})
+
+	t.Run("unknown", func(t *testing.T) {
+		if _, err := rr.Rate("USD", "GBP", d); err == nil {
+			t.Error("error expected, nil found")
+		}
+	})
+}
+
+func TestRateImportCSV(t *testing.T) {
+	t.Parallel()
+
+	fpath := filepath.Join(t.TempDir(), "eurofxref-hist.csv")
+	content := "Date,USD,JPY,CYP,\n2024-03-15,1.0890,161.23,N/A,\n2024-03-14,1.0887,161.48,N/A,\n"
+	if err := os.WriteFile(fpath, []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	rr := CreateRateRegistry()
+	n, err := rr.ImportCSV(fpath, "EUR")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != 4 {
+		t.Errorf("expected 4 rates imported, got: %d", n)
+	}
+
+	r := rr.Get("EUR", "JPY", time.Date(2024, time.March, 17, 0, 0, 0, 0, time.UTC))
+	if r == nil || r.Value.String() != "161.23" {
+		t.Errorf("expected EUR/JPY 161.23, got: %#v", r)
+	}
+}
+
+func TestNetWorth(t *testing.T) {
+	t.Parallel()
+
+	// Create repositories:
+	ar := CreateAccountRegistry()
+	tr := CreateTransactionRegistry()
+	br := CreateBalanceRegistry()
+	cr := CreateCurrencyRegistry()
+	tg := CreateTagRegistry()
+	tm := CreateTagsMapRegistry()
+
+	// Create service:
+	l := CreateLedger(ar, br, tr, cr, tg, tm)
+
+	openedAt := time.Date(2024, time.January, 1, 8, 30, 0, 0, time.UTC)
+	l.Rates().Create("EUR", "USD", openedAt, AmountFromInt(2))
+	l.Rates().Create("EUR", "JPY", openedAt, AmountFromInt(300))
+
+	bank, err := l.CreateAccount("Bank", Asset, "", "USD", openedAt, "100")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := l.CreateAccount("Wallet", Asset, "", "JPY", openedAt, "3000"); err != nil {
+		t.Fatal(err)
+	}
+	card, err := l.CreateAccount("Card", Liability, "", "EUR", openedAt, "10")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	v, err := l.NetWorth("EUR", openedAt)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 100 USD + 3000 JPY - 10 EUR = 50 EUR + 10 EUR - 10 EUR
+	if v.String() != "50" {
+		t.Errorf("expected net worth 50 EUR, got: %s", v)
+	}
+
+	// the later transactions are not counted:
+	if _, err := l.CreateTransaction(bank.ID, card.ID, openedAt.AddDate(0, 0, 1), "20", ""); err != nil {
+		t.Fatal(err)
+	}
+	if v, err := l.NetWorth("EUR", openedAt); err != nil || v.String() != "50" {
+		t.Errorf("expected net worth 50 EUR before the payment, got: %s (%v)", v, err)
+	}
+	if v, err := l.AccountBalanceIn(bank.ID, "EUR", openedAt.AddDate(0, 0, 1)); err != nil || v.String() != "40" {
+		t.Errorf("expected 40 EUR in bank after the payment, got: %s (%v)", v, err)
+	}
+
+	if _, err := l.NetWorth("USD", openedAt.AddDate(0, 0, -1)); err == nil {
+		t.Error("error expected for date without rates, nil found")
+	}
+}
@@ -0,0 +1,235 @@
+package miser
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+var ErrCleared = errors.New("cleared transaction cannot be changed, unclear it first")
+
+// Reconciliation is a record of finished reconciliation of account with a statement.
+type Reconciliation struct {
+	ID, Account      ID
+	StatementDate    time.Time // statement end date, transactions up to the end of this day are reconciled
+	StatementBalance Amount    // statement closing balance
+	Transactions     []ID      // transactions cleared by the reconciliation
+	FinishedAt       time.Time
+	Deleted          bool
+}
+
+type ReconciliationRegistry struct {
+	items  map[ID]Reconciliation
+	queued map[ID]Reconciliation
+
+	sync.RWMutex
+}
+
+func (rc *ReconciliationRegistry) List() map[ID]Reconciliation {
+	rc.RLock()
+	defer rc.RUnlock()
+	return rc.items
+}
+
+// Find the last reconciliation of account (by statement date).
+func (rc *ReconciliationRegistry) Last(accID ID) *Reconciliation {
+	rc.RLock()
+	defer rc.RUnlock()
+
+	var last *Reconciliation
+	for _, r := range rc.items {
+		if !r.Deleted && r.Account == accID && (last == nil || r.StatementDate.After(last.StatementDate)) {
+			last = &r
+		}
+	}
+	return last
+}
+
+func (rc *ReconciliationRegistry) Add(r Reconciliation) int {
+	rc.Lock()
+	defer rc.Unlock()
+	rc.items[r.ID] = r
+	return 1
+}
+
+func (rc *ReconciliationRegistry) AddQueued(r Reconciliation) {
+	rc.Lock()
+	defer rc.Unlock()
+	rc.queued[r.ID] = r
+}
+
+func (rc *ReconciliationRegistry) SyncQueued() (changes []Reconciliation) {
+	rc.RLock()
+	defer rc.RUnlock()
+	for _, r := range rc.queued {
+		changes = append(changes, r)
+	}
+	return
+}
+
+func CreateReconciliationRegistry() *ReconciliationRegistry {
+	return &ReconciliationRegistry{
+		items:  make(map[ID]Reconciliation),
+		queued: make(map[ID]Reconciliation),
+	}
+}
+
+func (rc *ReconciliationRegistry) Load() (int, error) { return Load(rc, RECONCILIATIONS_FILE) }
+func (rc *ReconciliationRegistry) Save() (int, error) { return Save(rc, RECONCILIATIONS_FILE) }
+
+// ReconciliationSession is reconciliation of account in progress: mark transactions
+// which are found in the statement as Pending until the difference is zero, then finish it.
+type ReconciliationSession struct {
+	l       *Ledger
+	acc     *Account
+	end     time.Time
+	balance Amount
+}
+
+// Start reconciliation of account with the statement of given end date and closing balance.
+func (l *Ledger) StartReconciliation(accID ID, end time.Time, closing string) (*ReconciliationSession, error) {
+	acc := l.ar.Get(accID)
+	if acc == nil {
+		return nil, errors.New("account not found")
+	}
+
+	if end.Before(acc.OpenedAt) {
+		return nil, errors.New("statement cannot be before the account is opened")
+	}
+
+	if last := l.rc.Last(accID); last != nil && end.Before(last.StatementDate) {
+		return nil, fmt.Errorf("account is already reconciled up to %s", last.StatementDate.Format(time.DateOnly))
+	}
+
+	balance, err := l.parseAmount(acc, closing)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ReconciliationSession{l: l, acc: acc, end: nextDay(end), balance: balance}, nil
+}
+
+// Transactions of account up to the statement end date, which are not cleared yet.
+func (s *ReconciliationSession) Transactions() (trs []Transaction) {
+	for _, t := range s.l.tr.Since(s.acc.ID, time.Time{}) {
+		if !t.Time.Before(s.end) {
+			break
+		}
+		if !t.IsInitial() && t.State != Cleared {
+			trs = append(trs, t)
+		}
+	}
+	return
+}
+
+// Mark transaction as found in the statement.
+func (s *ReconciliationSession) Mark(trID ID) error { return s.mark(trID, Pending) }
+
+// Unmark transaction marked by mistake.
+func (s *ReconciliationSession) Unmark(trID ID) error { return s.mark(trID, Uncleared) }
+
+func (s *ReconciliationSession) mark(trID ID, state int) error {
+	t := s.l.tr.Get(trID)
+	if t == nil || t.Deleted {
+		return errors.New("transaction not found")
+	}
+
+	if t.IsInitial() || (t.Source != s.acc.ID && t.Dest != s.acc.ID) {
+		return errors.New("transaction does not belong to reconciled account")
+	}
+
+	if !t.Time.Before(s.end) {
+		return errors.New("transaction is after the statement end date")
+	}
+
+	if t.State == Cleared {
+		return errors.New("transaction is already cleared")
+	}
+
+	s.l.setState(t, state)
+	return nil
+}
+
+// Balance of account counting only cleared and pending transactions up to the statement end date.
+func (s *ReconciliationSession) ClearedBalance() (value Amount) {
+	for _, t := range s.l.tr.Since(s.acc.ID, time.Time{}) {
+		if !t.Time.Before(s.end) {
+			break
+		}
+		switch {
+		case t.IsInitial():
+			value = t.Value
+		case t.State == Uncleared:
+		case t.Source == s.acc.ID:
+			value = value.Add(effect(string(s.acc.Type), Credit, t.Value))
+		default:
+			value = value.Add(effect(string(s.acc.Type), Debit, t.Value))
+		}
+	}
+	return
+}
+
+// Difference between the statement closing balance and the cleared balance.
+func (s *ReconciliationSession) Difference() Amount { return s.balance.Sub(s.ClearedBalance()) }
+
+// Finish reconciliation: all pending transactions become cleared,
+// the reconciliation record is created. The difference should be zero.
+func (s *ReconciliationSession) Finish() (*Reconciliation, error) {
+	if d := s.Difference(); !d.IsZero() {
+		return nil, fmt.Errorf("statement balance differs from cleared balance by %s", d)
+	}
+
+	r := Reconciliation{
+		ID:               CreateID(),
+		Account:          s.acc.ID,
+		StatementDate:    s.end.AddDate(0, 0, -1),
+		StatementBalance: s.balance,
+		FinishedAt:       time.Now(),
+	}
+
+	for _, t := range s.Transactions() {
+		if t.State == Pending {
+			s.l.setState(&t, Cleared)
+			r.Transactions = append(r.Transactions, t.ID)
+		}
+	}
+
+	s.l.rc.Add(r)
+	s.l.rc.AddQueued(r)
+	return &r, nil
+}
+
+// Cancel reconciliation, pending transactions become uncleared.
+func (s *ReconciliationSession) Cancel() {
+	for _, t := range s.Transactions() {
+		if t.State == Pending {
+			s.l.setState(&t, Uncleared)
+		}
+	}
+}
+
+// Reset state of cleared transaction to allow its changes.
+func (l *Ledger) UnclearTransaction(trID ID) error {
+	t := l.tr.Get(trID)
+	if t == nil || t.Deleted {
+		return errors.New("transaction not found")
+	}
+	l.setState(t, Uncleared)
+	return nil
+}
+
+func (l *Ledger) setState(t *Transaction, state int) {
+	if t.State == state {
+		return
+	}
+	t.State = state
+	l.tr.Add(*t)
+	l.tr.AddQueued(*t)
+}
+
+// Start of the day after given time, in its location.
+func nextDay(t time.Time) time.Time {
+	y, m, d := t.Date()
+	return time.Date(y, m, d+1, 0, 0, 0, 0, t.Location())
+}
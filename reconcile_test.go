@@ -0,0 +1,126 @@
+package miser
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestReconciliation(t *testing.T) {
+	t.Parallel()
+
+	// Create repositories:
+	ar := CreateAccountRegistry()
+	tr := CreateTransactionRegistry()
+	br := CreateBalanceRegistry()
+	cr := CreateCurrencyRegistry()
+	tg := CreateTagRegistry()
+	tm := CreateTagsMapRegistry()
+
+	// Create service:
+	l := CreateLedger(ar, br, tr, cr, tg, tm)
+
+	openedAt := time.Date(2024, time.May, 1, 9, 0, 0, 0, time.UTC)
+	bank, err := l.CreateAccount("Bank", Asset, "checking", "USD", openedAt, "1000")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	shop, err := l.CreateAccount("Shop", Expense, "groceries", "USD", openedAt, "0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var trs []*Transaction
+	for i, v := range []string{"100", "50", "25"} {
+		transa, err := l.CreateTransaction(bank.ID, shop.ID, openedAt.AddDate(0, 0, 10*i+1), v, "")
+		if err != nil {
+			t.Fatal(err)
+		}
+		trs = append(trs, transa)
+	}
+
+	// statement at May 15 includes only the first transaction:
+	end := time.Date(2024, time.May, 15, 0, 0, 0, 0, time.UTC)
+	s, err := l.StartReconciliation(bank.ID, end, "900")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if n := len(s.Transactions()); n != 2 {
+		t.Errorf("expected 2 transactions up to statement end date, got: %d", n)
+	}
+
+	if d := s.Difference(); d.String() != "-100" {
+		t.Errorf("expected difference -100, got: %s", d)
+	}
+
+	if _, err := s.Finish(); err == nil {
+		t.Error("error expected for finish with non zero difference, nil found")
+	}
+
+	if err := s.Mark(trs[2].ID); err == nil {
+		t.Error("error expected for transaction after statement end date, nil found")
+	}
+
+	if err := s.Mark(trs[1].ID); err != nil {
+		t.Fatal(err)
+	}
+	if d := s.Difference(); d.String() != "-50" {
+		t.Errorf("expected difference -50, got: %s", d)
+	}
+	if err := s.Unmark(trs[1].ID); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := s.Mark(trs[0].ID); err != nil {
+		t.Fatal(err)
+	}
+	if st := l.tr.Get(trs[0].ID).State; st != Pending {
+		t.Errorf("expected pending transaction, got state: %d", st)
+	}
+	if d := s.Difference(); !d.IsZero() {
+		t.Errorf("expected zero difference, got: %s", d)
+	}
+
+	r, err := s.Finish()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(r.Transactions) != 1 || r.Transactions[0] != trs[0].ID {
+		t.Errorf("unexpected reconciled transactions: %v", r.Transactions)
+	}
+	if last := l.Reconciliations().Last(bank.ID); last == nil || last.ID != r.ID {
+		t.Errorf("reconciliation was not persisted: %#v", last)
+	}
+
+	if st := l.tr.Get(trs[0].ID).State; st != Cleared {
+		t.Errorf("expected cleared transaction, got state: %d", st)
+	}
+	if st := l.tr.Get(trs[1].ID).State; st != Uncleared {
+		t.Errorf("expected uncleared transaction, got state: %d", st)
+	}
+
+	t.Run("protection", func(t *testing.T) {
+		if _, err := l.UpdateTransaction(trs[0].ID, bank.ID, shop.ID, trs[0].Time, "99", ""); !errors.Is(err, ErrCleared) {
+			t.Errorf("expected error of cleared transaction, got: %v", err)
+		}
+		if err := l.DeleteTransaction(trs[0].ID); !errors.Is(err, ErrCleared) {
+			t.Errorf("expected error of cleared transaction, got: %v", err)
+		}
+
+		if err := l.UnclearTransaction(trs[0].ID); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := l.UpdateTransaction(trs[0].ID, bank.ID, shop.ID, trs[0].Time, "99", ""); err != nil {
+			t.Errorf("unexpected error: %s", err)
+		}
+	})
+
+	t.Run("earlier statement", func(t *testing.T) {
+		if _, err := l.StartReconciliation(bank.ID, end.AddDate(0, 0, -1), "900"); err == nil {
+			t.Error("error expected for statement before the last reconciliation, nil found")
+		}
+	})
+}
@@ -0,0 +1,106 @@
+package miser
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+// Register is a list of transactions of account in chronological order with its running balance.
+type Register struct {
+	Account  ID
+	Name     string // full name of account
+	Currency string
+	Entries  []RegisterEntry
+}
+
+// RegisterEntry is a transaction seen from the account of register.
+type RegisterEntry struct {
+	Transaction ID
+	Time        time.Time
+	Counterpart ID     // the other account, empty for initial balance
+	Name        string // full name of counterpart
+	Text        string
+	Amount      Amount // signed effect on the balance of account
+	Balance     Amount // running balance of account after the transaction
+	State       int
+	Tags        []string
+}
+
+// Register of account: transactions matching the filters of query, e.g. the period [From, To)
+// or the query parsed by ParseQuery. The running balance does not depend on filters,
+// order, page and filter of deleted transactions of query are ignored.
+func (l *Ledger) Register(accID ID, q Query) (*Register, error) {
+	acc := l.ar.Get(accID)
+	if acc == nil {
+		return nil, errors.New("account not found")
+	}
+
+	q.Deleted = ExcludeDeleted
+	r := &Register{Account: accID, Name: acc.FullName(), Currency: string(acc.Cur)}
+	for _, t := range l.tr.Since(accID, q.From) {
+		if !q.To.IsZero() && !t.Time.Before(q.To) {
+			break
+		}
+		if !q.match(l, &t) {
+			continue
+		}
+
+		b := l.TransactionBalance(accID, t.ID)
+		if b == nil {
+			return nil, fmt.Errorf("balance not found, transaction ID: %s, account ID: %s", t.ID, accID)
+		}
+
+		e := RegisterEntry{
+			Transaction: t.ID, Time: t.Time, Text: string(t.Text),
+			Amount: t.Value, Balance: b.Value, State: t.State, Tags: l.transactionTags(t.ID),
+		}
+		switch {
+		case t.IsInitial():
+			e.Name = OpeningBalances
+		case t.Source == accID:
+			e.Counterpart, e.Amount = t.Dest, effect(string(acc.Type), Credit, t.Value)
+		default:
+			e.Counterpart, e.Amount = t.Source, effect(string(acc.Type), Debit, t.Value)
+		}
+		if e.Counterpart != "" {
+			e.Name = l.accountName(e.Counterpart)
+		}
+		r.Entries = append(r.Entries, e)
+	}
+	return r, nil
+}
+
+// Marks of transaction states used in text reports.
+var stateMarks = map[int]string{Uncleared: " ", Pending: "!", Cleared: "*"}
+
+// Render register as text table.
+func (r *Register) Render(digits int) string {
+	var sb strings.Builder
+	w := tabwriter.NewWriter(&sb, 0, 0, 2, ' ', 0)
+
+	fmt.Fprintf(w, "Register of %s, %s\n", r.Name, r.Currency)
+	for _, e := range r.Entries {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t%s\t\n",
+			e.Time.Format(time.DateOnly), stateMarks[e.State], e.Name, e.Text,
+			e.Amount.Format(digits), e.Balance.Format(digits), strings.Join(e.Tags, ","))
+	}
+
+	w.Flush()
+	return sb.String()
+}
+
+// Register of account rendered as text, the query is parsed by ParseQuery (empty means all transactions).
+func (l *Ledger) RegisterText(accID ID, query string) (string, error) {
+	q, err := l.ParseQuery(query)
+	if err != nil {
+		return "", err
+	}
+	r, err := l.Register(accID, q)
+	if err != nil {
+		return "", err
+	}
+	return r.Render(l.reportDigits(r.Currency)), nil
+}
@@ -0,0 +1,166 @@
+package miser
+
+import (
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRegister(t *testing.T) {
+	t.Parallel()
+
+	// Create repositories:
+	ar := CreateAccountRegistry()
+	tr := CreateTransactionRegistry()
+	br := CreateBalanceRegistry()
+	cr := CreateCurrencyRegistry()
+	tg := CreateTagRegistry()
+	tm := CreateTagsMapRegistry()
+
+	// Create service:
+	l := CreateLedger(ar, br, tr, cr, tg, tm)
+
+	openedAt := time.Date(2024, time.January, 1, 9, 0, 0, 0, time.UTC)
+	bank, err := l.CreateAccount("Bank", Asset, "checking", "USD", openedAt, "1000")
+	if err != nil {
+		t.Fatal(err)
+	}
+	salary, err := l.CreateAccount("Salary", Income, "", "USD", openedAt, "100000")
+	if err != nil {
+		t.Fatal(err)
+	}
+	groceries, err := l.CreateAccount("Food:Groceries", Expense, "", "USD", openedAt, "0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var trs []*Transaction
+	for _, d := range []struct {
+		src, dst ID
+		date     time.Time
+		v, text  string
+	}{
+		{salary.ID, bank.ID, time.Date(2024, time.January, 25, 12, 0, 0, 0, time.UTC), "2000", "salary of January"},
+		{bank.ID, groceries.ID, time.Date(2024, time.January, 10, 12, 0, 0, 0, time.UTC), "100", "milk and eggs"},
+		{bank.ID, groceries.ID, time.Date(2024, time.February, 3, 12, 0, 0, 0, time.UTC), "50", "bread"},
+		{bank.ID, groceries.ID, time.Date(2024, time.February, 5, 12, 0, 0, 0, time.UTC), "70", "mistake"},
+	} {
+		tr, err := l.CreateTransaction(d.src, d.dst, d.date, d.v, d.text)
+		if err != nil {
+			t.Fatal(err)
+		}
+		trs = append(trs, tr)
+	}
+	if err := l.DeleteTransaction(trs[3].ID); err != nil {
+		t.Fatal(err)
+	}
+	l.tagItem("food", trs[1].ID)
+	l.tagItem("bakery", trs[2].ID)
+	l.tagItem("food", trs[2].ID)
+	l.setState(trs[0], Cleared)
+
+	entries := func(r *Register) (s []string) {
+		for _, e := range r.Entries {
+			s = append(s, strings.Join([]string{
+				e.Time.Format(time.DateOnly), e.Name, e.Text, e.Amount.String(), e.Balance.String()}, " "))
+		}
+		return
+	}
+
+	t.Run("all", func(t *testing.T) {
+		r, err := l.Register(bank.ID, Query{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		want := []string{
+			"2024-01-01 " + OpeningBalances + " Initial balance 1000 1000",
+			"2024-01-10 Expense:Food:Groceries milk and eggs -100 900",
+			"2024-01-25 Income:Salary salary of January 2000 2900",
+			"2024-02-03 Expense:Food:Groceries bread -50 2850",
+		}
+		if got := entries(r); !slices.Equal(got, want) {
+			t.Errorf("expected entries %v, got: %v", want, got)
+		}
+		if r.Name != "Asset:Bank" || r.Currency != "USD" {
+			t.Errorf("expected register of Asset:Bank in USD, got: %s in %s", r.Name, r.Currency)
+		}
+
+		e := r.Entries[2]
+		if e.Counterpart != salary.ID || e.State != Cleared || e.Transaction != trs[0].ID {
+			t.Errorf("expected cleared salary entry, got: %+v", e)
+		}
+		if got := r.Entries[3].Tags; !slices.Equal(got, []string{"bakery", "food"}) {
+			t.Errorf("expected sorted tags of entry, got: %v", got)
+		}
+	})
+
+	t.Run("counterpart", func(t *testing.T) {
+		r, err := l.Register(groceries.ID, Query{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		want := []string{
+			"2024-01-01 " + OpeningBalances + " Initial balance 0 0",
+			"2024-01-10 Asset:Bank milk and eggs 100 100",
+			"2024-02-03 Asset:Bank bread 50 150",
+		}
+		if got := entries(r); !slices.Equal(got, want) {
+			t.Errorf("expected entries %v, got: %v", want, got)
+		}
+	})
+
+	t.Run("dates", func(t *testing.T) {
+		r, err := l.Register(bank.ID, Query{
+			From: time.Date(2024, time.January, 2, 0, 0, 0, 0, time.UTC),
+			To:   time.Date(2024, time.February, 1, 0, 0, 0, 0, time.UTC),
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		want := []string{
+			"2024-01-10 Expense:Food:Groceries milk and eggs -100 900",
+			"2024-01-25 Income:Salary salary of January 2000 2900",
+		}
+		if got := entries(r); !slices.Equal(got, want) {
+			t.Errorf("expected entries %v, got: %v", want, got)
+		}
+	})
+
+	t.Run("query", func(t *testing.T) {
+		q, err := l.ParseQuery("tag:food date:2024-02")
+		if err != nil {
+			t.Fatal(err)
+		}
+		r, err := l.Register(bank.ID, q)
+		if err != nil {
+			t.Fatal(err)
+		}
+		want := []string{"2024-02-03 Expense:Food:Groceries bread -50 2850"}
+		if got := entries(r); !slices.Equal(got, want) {
+			t.Errorf("expected entries %v, got: %v", want, got)
+		}
+	})
+
+	t.Run("text", func(t *testing.T) {
+		text, err := l.RegisterText(bank.ID, "")
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, want := range []string{"Register of Asset:Bank", "* ", "salary of January", "2850.00", "bakery,food"} {
+			if !strings.Contains(text, want) {
+				t.Errorf("expected %q in text:\n%s", want, text)
+			}
+		}
+		if strings.Contains(text, "mistake") {
+			t.Errorf("expected no deleted transactions in text:\n%s", text)
+		}
+
+		if _, err := l.RegisterText(bank.ID, "date:2024-13"); err == nil {
+			t.Error("expected error of query")
+		}
+		if _, err := l.RegisterText("unknown", ""); err == nil {
+			t.Error("expected error of unknown account")
+		}
+	})
+}
@@ -0,0 +1,150 @@
+package miser
+
+import (
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+	"time"
+)
+
+// ReportColumn is a period of report: days From..To (inclusive).
+type ReportColumn struct {
+	Name     string
+	From, To time.Time
+}
+
+// ReportLine is a row of report: an account or a total of subtree of accounts.
+type ReportLine struct {
+	Name    string   // full name of account or subtree, e.g. Expense:Food:Groceries
+	Account ID       // empty for subtree without account of the same name
+	Depth   int      // level in the tree of accounts, 1 - top accounts of type
+	Values  []Amount // per column, subtree values include their children
+}
+
+// Previous period of the same length: the same number of months for the whole months, days otherwise.
+func previousPeriod(from, to time.Time) (time.Time, time.Time) {
+	end := nextDay(to)
+	if from.Day() == 1 && end.Day() == 1 {
+		months := (end.Year()-from.Year())*12 + int(end.Month()-from.Month())
+		return from.AddDate(0, -months, 0), from.AddDate(0, 0, -1)
+	}
+	days := int(end.Sub(from).Hours()+12) / 24
+	return from.AddDate(0, 0, -days), from.AddDate(0, 0, -1)
+}
+
+// The same period a year before: whole months are shifted by 12 months to the month end,
+// other days are clamped to the end of month, e.g. 2024-02-29 is 2023-02-28.
+func lastYearPeriod(from, to time.Time) (time.Time, time.Time) {
+	end := nextDay(to)
+	if from.Day() == 1 && end.Day() == 1 {
+		return from.AddDate(-1, 0, 0), end.AddDate(-1, 0, -1)
+	}
+	return yearBefore(from), yearBefore(to)
+}
+
+// The same day a year before, clamped to the end of month.
+func yearBefore(t time.Time) time.Time {
+	y, m, d := t.Date()
+	last := time.Date(y-1, m+1, 0, 0, 0, 0, 0, t.Location()).Day()
+	return time.Date(y-1, m, min(d, last), t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), t.Location())
+}
+
+// Columns of report comparing the period with the previous one and the same period last year.
+func comparisonColumns(from, to time.Time) []ReportColumn {
+	prevFrom, prevTo := previousPeriod(from, to)
+	lastFrom, lastTo := lastYearPeriod(from, to)
+	return []ReportColumn{
+		{Name: "Current", From: from, To: to},
+		{Name: "Previous", From: prevFrom, To: prevTo},
+		{Name: "Last year", From: lastFrom, To: lastTo},
+	}
+}
+
+// Build tree of report lines from values of accounts: every subtree gets the total of its accounts,
+// lines of zero values are skipped, lines are ordered by names with parents before children.
+func reportTree(accounts []Account, values map[ID][]Amount, columns int) (lines []ReportLine) {
+	nodes := make(map[string]*ReportLine)
+	for _, acc := range accounts {
+		v, ok := values[acc.ID]
+		if !ok || !slices.ContainsFunc(v, func(a Amount) bool { return !a.IsZero() }) {
+			continue
+		}
+
+		parts := strings.Split(acc.FullName(), ":")
+		for depth := 1; depth < len(parts); depth++ {
+			name := strings.Join(parts[:depth+1], ":")
+			node := nodes[name]
+			if node == nil {
+				node = &ReportLine{Name: name, Depth: depth, Values: make([]Amount, columns)}
+				nodes[name] = node
+			}
+			for i := range node.Values {
+				node.Values[i] = node.Values[i].Add(v[i])
+			}
+			if depth == len(parts)-1 {
+				node.Account = acc.ID
+			}
+		}
+	}
+
+	for _, node := range nodes {
+		lines = append(lines, *node)
+	}
+	slices.SortFunc(lines, func(a, b ReportLine) int {
+		return slices.Compare(strings.Split(a.Name, ":"), strings.Split(b.Name, ":"))
+	})
+	return
+}
+
+// Totals of top level lines.
+func reportTotals(lines []ReportLine, columns int) []Amount {
+	totals := make([]Amount, columns)
+	for _, line := range lines {
+		if line.Depth != 1 {
+			continue
+		}
+		for i, v := range line.Values {
+			totals[i] = totals[i].Add(v)
+		}
+	}
+	return totals
+}
+
+// Writer of text table of report, use it with tabwriter.
+type reportWriter struct {
+	w      io.Writer
+	digits int
+}
+
+func (rw reportWriter) header(title string, columns []ReportColumn) {
+	fmt.Fprint(rw.w, title)
+	for _, c := range columns {
+		fmt.Fprintf(rw.w, "\t%s..%s", c.From.Format(time.DateOnly), c.To.Format(time.DateOnly))
+	}
+	fmt.Fprintln(rw.w, "\t")
+}
+
+func (rw reportWriter) row(label string, values []Amount) {
+	fmt.Fprint(rw.w, label)
+	for _, v := range values {
+		fmt.Fprintf(rw.w, "\t%s", v.Format(rw.digits))
+	}
+	fmt.Fprintln(rw.w, "\t")
+}
+
+// Rows of lines indented by depth, the last part of name is shown.
+func (rw reportWriter) lines(lines []ReportLine) {
+	for _, line := range lines {
+		name := line.Name[strings.LastIndexByte(line.Name, ':')+1:]
+		rw.row(strings.Repeat("  ", line.Depth)+name, line.Values)
+	}
+}
+
+// Number of fraction digits of amounts of currency in reports.
+func (l *Ledger) reportDigits(cur string) int {
+	if c := l.cr.Get(cur); c != nil {
+		return c.Digits()
+	}
+	return 2
+}
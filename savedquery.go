@@ -0,0 +1,111 @@
+package miser
+
+import (
+	"errors"
+	"strings"
+	"sync"
+)
+
+// SavedQuery is a named query string for reuse.
+type SavedQuery struct {
+	ID         ID
+	Name, Text EncryptedString
+	Deleted    bool
+}
+
+type SavedQueryRegistry struct {
+	items  map[ID]SavedQuery
+	queued map[ID]SavedQuery
+
+	sync.RWMutex
+}
+
+func (sq *SavedQueryRegistry) List() map[ID]SavedQuery {
+	sq.RLock()
+	defer sq.RUnlock()
+	return sq.items
+}
+
+func (sq *SavedQueryRegistry) GetByName(n string) *SavedQuery {
+	sq.RLock()
+	defer sq.RUnlock()
+
+	for _, q := range sq.items {
+		if !q.Deleted && string(q.Name) == n {
+			return &q
+		}
+	}
+	return nil
+}
+
+func (sq *SavedQueryRegistry) Add(q SavedQuery) int {
+	sq.Lock()
+	defer sq.Unlock()
+	sq.items[q.ID] = q
+	return 1
+}
+
+func (sq *SavedQueryRegistry) AddQueued(q SavedQuery) {
+	sq.Lock()
+	defer sq.Unlock()
+	sq.queued[q.ID] = q
+}
+
+func (sq *SavedQueryRegistry) SyncQueued() (changes []SavedQuery) {
+	sq.RLock()
+	defer sq.RUnlock()
+	for _, q := range sq.queued {
+		changes = append(changes, q)
+	}
+	return
+}
+
+func CreateSavedQueryRegistry() *SavedQueryRegistry {
+	return &SavedQueryRegistry{
+		items:  make(map[ID]SavedQuery),
+		queued: make(map[ID]SavedQuery),
+	}
+}
+
+func (sq *SavedQueryRegistry) Load() (int, error) { return Load(sq, SAVED_QUERIES_FILE) }
+func (sq *SavedQueryRegistry) Save() (int, error) { return Save(sq, SAVED_QUERIES_FILE) }
+
+// Save query string under given name, the query with the same name is replaced.
+func (l *Ledger) SaveQuery(name, text string) (*SavedQuery, error) {
+	if strings.TrimSpace(name) == "" {
+		return nil, errors.New("blank name of query is not allowed")
+	}
+
+	if _, err := l.ParseQuery(text); err != nil {
+		return nil, err
+	}
+
+	q := SavedQuery{ID: CreateID(), Name: EncryptedString(name), Text: EncryptedString(text)}
+	if old := l.sq.GetByName(name); old != nil {
+		q.ID = old.ID
+	}
+	l.sq.Add(q)
+	l.sq.AddQueued(q)
+	return &q, nil
+}
+
+// Delete saved query.
+func (l *Ledger) DeleteQuery(name string) error {
+	q := l.sq.GetByName(name)
+	if q == nil {
+		return errors.New("query not found")
+	}
+	q.Deleted = true
+	l.sq.Add(*q)
+	l.sq.AddQueued(*q)
+	return nil
+}
+
+// Parse saved query of given name.
+func (l *Ledger) NamedQuery(name string) (Query, error) {
+	q := l.sq.GetByName(name)
+	if q == nil {
+		return Query{}, errors.New("query not found")
+	}
+	return l.ParseQuery(string(q.Text))
+}
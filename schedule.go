@@ -0,0 +1,264 @@
+package miser
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+)
+
+// Frequencies of recurrence.
+const (
+	Daily = iota
+	Weekly
+	Monthly
+	Yearly
+)
+
+// The last day of month, use it as Day of monthly recurrence.
+const LastDay = -1
+
+// Recurrence describes dates of occurrences, e.g.:
+//
+//	Recurrence{Freq: Monthly, Interval: 1, Day: 25}                         // monthly on the 25th
+//	Recurrence{Freq: Weekly, Interval: 2}                                   // every 2 weeks
+//	Recurrence{Freq: Monthly, Interval: 1, Day: LastDay, BusinessDay: true} // the last business day
+type Recurrence struct {
+	Freq, Interval int       // e.g. Weekly, 2 - every 2 weeks
+	Day            int       // day of month (1..31 or LastDay) of Monthly recurrence, 0 - the day of Start
+	BusinessDay    bool      // move occurrences at weekend to the previous Friday
+	Start, Until   time.Time // the first occurrence (and time of day of all ones), optional end
+}
+
+func (r Recurrence) validate() error {
+	if r.Freq < Daily || r.Freq > Yearly {
+		return fmt.Errorf("wrong frequency of recurrence: %d", r.Freq)
+	}
+	if r.Interval < 1 {
+		return errors.New("interval of recurrence should be greater zero")
+	}
+	if r.Day < LastDay || r.Day > 31 {
+		return fmt.Errorf("wrong day of month: %d", r.Day)
+	}
+	if r.Start.IsZero() {
+		return errors.New("zero start of recurrence is not allowed")
+	}
+	if !r.Until.IsZero() && r.Until.Before(r.Start) {
+		return errors.New("end of recurrence cannot be before its start")
+	}
+	return nil
+}
+
+// The n-th occurrence of recurrence (before the business day adjustment).
+func (r Recurrence) nth(n int) time.Time {
+	s := r.Start
+	switch r.Freq {
+	case Daily:
+		return s.AddDate(0, 0, n*r.Interval)
+	case Weekly:
+		return s.AddDate(0, 0, 7*n*r.Interval)
+	case Yearly:
+		return monthDay(s, s.Year()+n*r.Interval, s.Month(), s.Day())
+	}
+
+	day := r.Day
+	if day == 0 {
+		day = s.Day()
+	}
+	return monthDay(s, s.Year(), s.Month()+time.Month(n*r.Interval), day)
+}
+
+// Occurrences of recurrence after the given time (exclusive) up to the given time (inclusive),
+// dates before Start are skipped, weekend occurrences moved to the same Friday are merged.
+func (r Recurrence) Occurrences(after, until time.Time) (dates []time.Time) {
+	if !r.Until.IsZero() && r.Until.Before(until) {
+		until = r.Until
+	}
+
+	// occurrences are moved back by 2 days at most, the later ones could get into the range:
+	last := after
+	for n, end := 0, until.AddDate(0, 0, 2); ; n++ {
+		t := r.nth(n)
+		if t.After(end) {
+			return
+		}
+		t = r.businessDay(t)
+		if t.Before(r.Start) || !t.After(last) || t.After(until) {
+			continue
+		}
+		dates = append(dates, t)
+		last = t
+	}
+}
+
+// Move occurrence at weekend to the previous Friday if it is required.
+func (r Recurrence) businessDay(t time.Time) time.Time {
+	if !r.BusinessDay {
+		return t
+	}
+	switch t.Weekday() {
+	case time.Saturday:
+		return t.AddDate(0, 0, -1)
+	case time.Sunday:
+		return t.AddDate(0, 0, -2)
+	}
+	return t
+}
+
+// Date at given year, month and day (clamped to the month length or LastDay)
+// with time of day of given time.
+func monthDay(t time.Time, year int, month time.Month, day int) time.Time {
+	first := time.Date(year, month, 1, t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), t.Location())
+	last := first.AddDate(0, 1, -1).Day()
+	if day == LastDay || day > last {
+		day = last
+	}
+	return first.AddDate(0, 0, day-1)
+}
+
+// Schedule is a definition of recurring transaction.
+type Schedule struct {
+	ID, Source, Dest ID
+	Value            Amount
+	Text             EncryptedString
+	Recurrence
+	Posted  time.Time // the last posted occurrence
+	Deleted bool
+}
+
+type ScheduleRegistry struct {
+	items  map[ID]Schedule
+	queued map[ID]Schedule
+
+	sync.RWMutex
+}
+
+func (sr *ScheduleRegistry) List() map[ID]Schedule {
+	sr.RLock()
+	defer sr.RUnlock()
+	return sr.items
+}
+
+func (sr *ScheduleRegistry) Get(schID ID) *Schedule {
+	sr.RLock()
+	defer sr.RUnlock()
+	s, ok := sr.items[schID]
+	if ok {
+		return &s
+	}
+	return nil
+}
+
+func (sr *ScheduleRegistry) Add(s Schedule) int {
+	sr.Lock()
+	defer sr.Unlock()
+	sr.items[s.ID] = s
+	return 1
+}
+
+func (sr *ScheduleRegistry) AddQueued(s Schedule) {
+	sr.Lock()
+	defer sr.Unlock()
+	sr.queued[s.ID] = s
+}
+
+func (sr *ScheduleRegistry) SyncQueued() (changes []Schedule) {
+	sr.RLock()
+	defer sr.RUnlock()
+	for _, s := range sr.queued {
+		changes = append(changes, s)
+	}
+	return
+}
+
+func CreateScheduleRegistry() *ScheduleRegistry {
+	return &ScheduleRegistry{
+		items:  make(map[ID]Schedule),
+		queued: make(map[ID]Schedule),
+	}
+}
+
+func (sr *ScheduleRegistry) Load() (int, error) { return Load(sr, SCHEDULES_FILE) }
+func (sr *ScheduleRegistry) Save() (int, error) { return Save(sr, SCHEDULES_FILE) }
+
+// Create a definition of recurring transaction.
+func (l *Ledger) CreateSchedule(src, dst ID, v string, txt string, r Recurrence) (*Schedule, error) {
+	if err := r.validate(); err != nil {
+		return nil, err
+	}
+
+	srcAcc := l.ar.Get(src)
+	if srcAcc == nil {
+		return nil, errors.New("src account not found")
+	}
+
+	dstAcc := l.ar.Get(dst)
+	if dstAcc == nil {
+		return nil, errors.New("dst account not found")
+	}
+
+	if srcAcc.Type == dstAcc.Type {
+		return nil, errors.New("cannot be transferred to same type of account")
+	}
+
+	value, err := l.parseAmount(srcAcc, v)
+	if err != nil {
+		return nil, err
+	}
+	if value.Sign() <= 0 {
+		return nil, errors.New("transaction value should be greater zero")
+	}
+
+	s := Schedule{ID: CreateID(), Source: src, Dest: dst, Value: value, Text: EncryptedString(txt), Recurrence: r}
+	l.sc.Add(s)
+	l.sc.AddQueued(s)
+	return &s, nil
+}
+
+// Post all due occurrences of scheduled transactions up to given time (inclusive),
+// the already posted ones are skipped. Posted transactions are tagged as Periodic.
+func (l *Ledger) PostScheduled(until time.Time) (posted []Transaction, err error) {
+	type occurrence struct {
+		schID ID
+		t     time.Time
+	}
+
+	l.refreshSpendings()
+
+	var due []occurrence
+	for _, s := range l.sc.List() {
+		if s.Deleted {
+			continue
+		}
+		for _, t := range s.Occurrences(s.Posted, until) {
+			due = append(due, occurrence{s.ID, t})
+		}
+	}
+
+	// post in chronological order, the balance checks depend on it:
+	slices.SortFunc(due, func(a, b occurrence) int {
+		if c := a.t.Compare(b.t); c != 0 {
+			return c
+		}
+		return cmp.Compare(a.schID, b.schID)
+	})
+
+	for _, o := range due {
+		s := l.sc.Get(o.schID)
+		transa, err := l.postTransaction(s.Source, s.Dest, o.t, s.Value.String(), string(s.Text))
+		if err != nil {
+			return posted, fmt.Errorf("schedule %s at %s: %w", s.ID, o.t.Format(time.DateOnly), err)
+		}
+		l.tagItem(Periodic, transa.ID)
+		l.inspectSpending(transa, false) // scheduled spending is expected
+
+		s.Posted = o.t
+		l.sc.Add(*s)
+		l.sc.AddQueued(*s)
+
+		posted = append(posted, *transa)
+	}
+	return posted, nil
+}
@@ -0,0 +1,227 @@
+package miser
+
+import (
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestRecurrence(t *testing.T) {
+	t.Parallel()
+
+	start := time.Date(2024, time.January, 25, 9, 0, 0, 0, time.UTC)
+	until := time.Date(2024, time.May, 31, 23, 0, 0, 0, time.UTC)
+
+	dates := func(r Recurrence, after time.Time) (days []string) {
+		for _, d := range r.Occurrences(after, until) {
+			days = append(days, d.Format(time.DateOnly))
+		}
+		return
+	}
+
+	cases := []struct {
+		name string
+		r    Recurrence
+		want []string
+	}{
+		{
+			"monthly on the 25th",
+			Recurrence{Freq: Monthly, Interval: 1, Day: 25, Start: start},
+			[]string{"2024-01-25", "2024-02-25", "2024-03-25", "2024-04-25", "2024-05-25"},
+		},
+		{
+			"every 2 weeks",
+			Recurrence{Freq: Weekly, Interval: 2, Start: start, Until: time.Date(2024, time.March, 31, 0, 0, 0, 0, time.UTC)},
+			[]string{"2024-01-25", "2024-02-08", "2024-02-22", "2024-03-07", "2024-03-21"},
+		},
+		{
+			"last business day",
+			Recurrence{Freq: Monthly, Interval: 1, Day: LastDay, BusinessDay: true, Start: start},
+			[]string{"2024-01-31", "2024-02-29", "2024-03-29", "2024-04-30", "2024-05-31"},
+		},
+		{
+			"the 31st is clamped",
+			Recurrence{Freq: Monthly, Interval: 2, Day: 31, Start: start},
+			[]string{"2024-01-31", "2024-03-31", "2024-05-31"},
+		},
+	}
+	for _, c := range cases {
+		if got := dates(c.r, time.Time{}); !slices.Equal(got, c.want) {
+			t.Errorf("%s: expected %v, got: %v", c.name, c.want, got)
+		}
+	}
+
+	r := cases[0].r
+	if got := dates(r, time.Date(2024, time.March, 25, 9, 0, 0, 0, time.UTC)); !slices.Equal(got, []string{"2024-04-25", "2024-05-25"}) {
+		t.Errorf("expected occurrences after March 25th, got: %v", got)
+	}
+
+	// the day of month before the day of start:
+	r = Recurrence{Freq: Monthly, Interval: 1, Day: 25, Start: time.Date(2024, time.January, 30, 9, 0, 0, 0, time.UTC)}
+	if got := dates(r, time.Time{}); !slices.Equal(got, []string{"2024-02-25", "2024-03-25", "2024-04-25", "2024-05-25"}) {
+		t.Errorf("expected no occurrences before start, got: %v", got)
+	}
+
+	// Saturday, August 31 is moved back into the range:
+	r = Recurrence{Freq: Monthly, Interval: 1, Day: LastDay, BusinessDay: true, Start: start}
+	var got []string
+	for _, d := range r.Occurrences(time.Time{}, time.Date(2024, time.August, 30, 12, 0, 0, 0, time.UTC)) {
+		got = append(got, d.Format(time.DateOnly))
+	}
+	if want := []string{"2024-01-31", "2024-02-29", "2024-03-29", "2024-04-30", "2024-05-31", "2024-06-28", "2024-07-31", "2024-08-30"}; !slices.Equal(got, want) {
+		t.Errorf("expected %v, got: %v", want, got)
+	}
+
+	// daily business days: weekends are merged into Friday, Friday before Saturday start is skipped:
+	weekdays := []string{"2024-06-03", "2024-06-04", "2024-06-05", "2024-06-06", "2024-06-07",
+		"2024-06-10", "2024-06-11", "2024-06-12", "2024-06-13", "2024-06-14"}
+	for _, c := range []struct {
+		name  string
+		start time.Time
+		after time.Time
+		want  []string
+	}{
+		{"from Monday", time.Date(2024, time.June, 3, 9, 0, 0, 0, time.UTC), time.Time{}, weekdays},
+		{"from Saturday", time.Date(2024, time.June, 1, 9, 0, 0, 0, time.UTC), time.Time{}, weekdays},
+		{"after Friday", time.Date(2024, time.June, 3, 9, 0, 0, 0, time.UTC), time.Date(2024, time.June, 7, 9, 0, 0, 0, time.UTC), weekdays[5:]},
+	} {
+		r := Recurrence{Freq: Daily, Interval: 1, BusinessDay: true, Start: c.start}
+		var got []string
+		for _, d := range r.Occurrences(c.after, time.Date(2024, time.June, 16, 23, 0, 0, 0, time.UTC)) {
+			got = append(got, d.Format(time.DateOnly))
+		}
+		if !slices.Equal(got, c.want) {
+			t.Errorf("daily business days %s: expected %v, got: %v", c.name, c.want, got)
+		}
+	}
+}
+
+func TestPostScheduled(t *testing.T) {
+	t.Parallel()
+
+	// Create repositories:
+	ar := CreateAccountRegistry()
+	tr := CreateTransactionRegistry()
+	br := CreateBalanceRegistry()
+	cr := CreateCurrencyRegistry()
+	tg := CreateTagRegistry()
+	tm := CreateTagsMapRegistry()
+
+	// Create service:
+	l := CreateLedger(ar, br, tr, cr, tg, tm)
+
+	openedAt := time.Date(2024, time.January, 1, 8, 0, 0, 0, time.UTC)
+	bank, err := l.CreateAccount("Bank", Asset, "checking", "USD", openedAt, "5000")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	landlord, err := l.CreateAccount("Rent", Expense, "apartment", "USD", openedAt, "0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	r := Recurrence{Freq: Monthly, Interval: 1, Day: 25, Start: time.Date(2024, time.January, 25, 9, 0, 0, 0, time.UTC)}
+	if _, err := l.CreateSchedule(bank.ID, landlord.ID, "1200", "rent", r); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := l.CreateSchedule(bank.ID, landlord.ID, "1200", "rent", Recurrence{Freq: Monthly, Start: r.Start}); err == nil {
+		t.Error("error expected for zero interval, nil found")
+	}
+
+	posted, err := l.PostScheduled(time.Date(2024, time.March, 1, 0, 0, 0, 0, time.UTC))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(posted) != 2 {
+		t.Fatalf("expected 2 posted transactions, got: %d", len(posted))
+	}
+
+	tag := tg.GetByName(Periodic)
+	if tag == nil {
+		t.Fatal("tag Periodic not found")
+	}
+	for _, transa := range posted {
+		if !slices.Contains(tm.Tags(transa.ID), tag.ID) {
+			t.Errorf("transaction %s is not tagged as periodic", transa.ID)
+		}
+	}
+
+	// the posted ones are skipped:
+	posted, err = l.PostScheduled(time.Date(2024, time.April, 1, 0, 0, 0, 0, time.UTC))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(posted) != 1 || posted[0].Time.Day() != 25 || posted[0].Time.Month() != time.March {
+		t.Errorf("expected only March rent to be posted, got: %#v", posted)
+	}
+
+	if s := l.AccountBalance(bank.ID).Value.String(); s != "1400" {
+		t.Errorf("expected 1400 in bank, got: %s", s)
+	}
+
+	// balance is not enough for the next rents:
+	posted, err = l.PostScheduled(time.Date(2024, time.June, 1, 0, 0, 0, 0, time.UTC))
+	if err == nil {
+		t.Error("error expected for overdraft, nil found")
+	}
+	if len(posted) != 1 {
+		t.Errorf("expected April rent to be posted, got: %#v", posted)
+	}
+}
+
+func TestPostScheduledBusinessDays(t *testing.T) {
+	t.Parallel()
+
+	// Create repositories:
+	ar := CreateAccountRegistry()
+	tr := CreateTransactionRegistry()
+	br := CreateBalanceRegistry()
+	cr := CreateCurrencyRegistry()
+	tg := CreateTagRegistry()
+	tm := CreateTagsMapRegistry()
+
+	// Create service:
+	l := CreateLedger(ar, br, tr, cr, tg, tm)
+
+	openedAt := time.Date(2024, time.June, 1, 8, 0, 0, 0, time.UTC) // Saturday
+	bank, err := l.CreateAccount("Bank", Asset, "checking", "USD", openedAt, "1000")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	parking, err := l.CreateAccount("Parking", Expense, "", "USD", openedAt, "0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	r := Recurrence{Freq: Daily, Interval: 1, BusinessDay: true, Start: time.Date(2024, time.June, 1, 9, 0, 0, 0, time.UTC)}
+	if _, err := l.CreateSchedule(bank.ID, parking.ID, "10", "parking", r); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, c := range []struct {
+		until time.Time
+		want  []string
+	}{
+		{time.Date(2024, time.June, 9, 23, 0, 0, 0, time.UTC), []string{"2024-06-03", "2024-06-04", "2024-06-05", "2024-06-06", "2024-06-07"}},
+		{time.Date(2024, time.June, 10, 23, 0, 0, 0, time.UTC), []string{"2024-06-10"}}, // the weekend is posted once
+	} {
+		posted, err := l.PostScheduled(c.until)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var days []string
+		for _, transa := range posted {
+			days = append(days, transa.Time.Format(time.DateOnly))
+		}
+		if !slices.Equal(days, c.want) {
+			t.Errorf("expected posted %v, got: %v", c.want, days)
+		}
+	}
+
+	if s := l.AccountBalance(bank.ID).Value.String(); s != "940" {
+		t.Errorf("expected 940 in bank, got: %s", s)
+	}
+}
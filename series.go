@@ -0,0 +1,197 @@
+package miser
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// SeriesPoint is a balance at the end of period.
+type SeriesPoint struct {
+	Start, End time.Time // period [Start, End)
+	Value      Amount
+}
+
+// Start of period of given frequency containing the time: day, week (from Monday),
+// month or year, in location of the time.
+func periodStart(freq int, t time.Time) time.Time {
+	y, m, d := t.Date()
+	switch freq {
+	case Weekly:
+		return time.Date(y, m, d-(int(t.Weekday())+6)%7, 0, 0, 0, 0, t.Location())
+	case Monthly:
+		return time.Date(y, m, 1, 0, 0, 0, 0, t.Location())
+	case Yearly:
+		return time.Date(y, time.January, 1, 0, 0, 0, 0, t.Location())
+	}
+	return time.Date(y, m, d, 0, 0, 0, 0, t.Location())
+}
+
+// Start of the next period of given frequency.
+func nextPeriod(freq int, start time.Time) time.Time {
+	switch freq {
+	case Weekly:
+		return start.AddDate(0, 0, 7)
+	case Monthly:
+		return start.AddDate(0, 1, 0)
+	case Yearly:
+		return start.AddDate(1, 0, 0)
+	}
+	return start.AddDate(0, 0, 1)
+}
+
+// Periods of given frequency covering days from..to (inclusive), the last one is cut at the end of to.
+func periods(freq int, from, to time.Time) ([]SeriesPoint, error) {
+	if freq < Daily || freq > Yearly {
+		return nil, fmt.Errorf("wrong frequency of series: %d", freq)
+	}
+	if to.Before(from) {
+		return nil, errors.New("end of series cannot be before its start")
+	}
+
+	end := nextDay(to)
+	var points []SeriesPoint
+	for start := periodStart(freq, from); start.Before(end); start = nextPeriod(freq, start) {
+		p := SeriesPoint{Start: start, End: nextPeriod(freq, start)}
+		if p.End.After(end) {
+			p.End = end
+		}
+		points = append(points, p)
+	}
+	return points, nil
+}
+
+// Balances of account at the ends of periods: one pass over its ordered transactions,
+// the periods without transactions carry the last balance forward.
+func (l *Ledger) accountSeries(accID ID, points []SeriesPoint) ([]Amount, error) {
+	_, trs := l.tr.snapshot(accID)
+	values := make([]Amount, len(points))
+
+	// running balances of derived mode are computed once for all transactions:
+	balance := func(trID ID) (Amount, bool) {
+		b := l.br.TransactionBalance(accID, trID)
+		if b == nil {
+			return Amount{}, false
+		}
+		return b.Value, true
+	}
+	if l.mode == DerivedBalances {
+		d := l.derive(accID)
+		if d == nil {
+			return nil, errors.New("account not found")
+		}
+		balance = func(trID ID) (Amount, bool) {
+			i, ok := d.pos[trID]
+			if !ok {
+				return Amount{}, false
+			}
+			return d.values[i], true
+		}
+	}
+
+	var value Amount
+	i := 0
+	for n, p := range points {
+		for ; i < len(trs) && trs[i].Time.Before(p.End); i++ {
+			v, ok := balance(trs[i].ID)
+			if !ok {
+				return nil, fmt.Errorf("balance not found, transaction ID: %s, account ID: %s", trs[i].ID, accID)
+			}
+			value = v
+		}
+		values[n] = value
+	}
+	return values, nil
+}
+
+// Daily, Weekly, Monthly or Yearly series of balances of account over days from..to.
+func (l *Ledger) BalanceSeries(accID ID, freq int, from, to time.Time) ([]SeriesPoint, error) {
+	if l.ar.Get(accID) == nil {
+		return nil, errors.New("account not found")
+	}
+
+	points, err := periods(freq, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	values, err := l.accountSeries(accID, points)
+	if err != nil {
+		return nil, err
+	}
+	for i := range points {
+		points[i].Value = values[i]
+	}
+	return points, nil
+}
+
+// Series of total balance of accounts converted to given currency at the ends of periods.
+func (l *Ledger) sumSeries(accounts []Account, cur string, freq int, from, to time.Time) ([]SeriesPoint, error) {
+	if l.cr.Get(cur) == nil {
+		return nil, fmt.Errorf("currency %q is not supproted", cur)
+	}
+
+	points, err := periods(freq, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, acc := range accounts {
+		values, err := l.accountSeries(acc.ID, points)
+		if err != nil {
+			return nil, err
+		}
+		for i, v := range values {
+			if v.IsZero() {
+				continue
+			}
+			v, err := l.Convert(v, string(acc.Cur), cur, points[i].End.Add(-time.Nanosecond))
+			if err != nil {
+				return nil, err
+			}
+			points[i].Value = points[i].Value.Add(v)
+		}
+	}
+	return points, nil
+}
+
+// Series of total balance of account subtree (by full name, e.g. Expense:Food) in given currency.
+func (l *Ledger) SubtreeSeries(name, cur string, freq int, from, to time.Time) ([]SeriesPoint, error) {
+	var accounts []Account
+	for _, acc := range l.ar.List() {
+		if !acc.Deleted && acc.Under(name) {
+			accounts = append(accounts, acc)
+		}
+	}
+	if len(accounts) == 0 {
+		return nil, fmt.Errorf("no accounts found: %s", name)
+	}
+	return l.sumSeries(accounts, cur, freq, from, to)
+}
+
+// Series of net worth (assets minus liabilities) in given currency.
+func (l *Ledger) NetWorthSeries(cur string, freq int, from, to time.Time) ([]SeriesPoint, error) {
+	var assets, liabilities []Account
+	for _, acc := range l.ar.List() {
+		switch {
+		case acc.Deleted:
+		case acc.Type == Asset:
+			assets = append(assets, acc)
+		case acc.Type == Liability:
+			liabilities = append(liabilities, acc)
+		}
+	}
+
+	points, err := l.sumSeries(assets, cur, freq, from, to)
+	if err != nil {
+		return nil, err
+	}
+	debts, err := l.sumSeries(liabilities, cur, freq, from, to)
+	if err != nil {
+		return nil, err
+	}
+	for i := range points {
+		points[i].Value = points[i].Value.Sub(debts[i].Value)
+	}
+	return points, nil
+}
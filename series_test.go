@@ -0,0 +1,169 @@
+package miser
+
+import (
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestSeries(t *testing.T) {
+	t.Parallel()
+
+	// Create repositories:
+	ar := CreateAccountRegistry()
+	tr := CreateTransactionRegistry()
+	br := CreateBalanceRegistry()
+	cr := CreateCurrencyRegistry()
+	tg := CreateTagRegistry()
+	tm := CreateTagsMapRegistry()
+
+	// Create service:
+	l := CreateLedger(ar, br, tr, cr, tg, tm)
+
+	openedAt := time.Date(2024, time.January, 1, 9, 0, 0, 0, time.UTC) // Monday
+	l.Rates().Create("EUR", "USD", openedAt, AmountFromInt(2))
+
+	bank, err := l.CreateAccount("Bank", Asset, "checking", "USD", openedAt, "1000")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := l.CreateAccount("Card", Liability, "credit card", "EUR", openedAt, "100"); err != nil {
+		t.Fatal(err)
+	}
+	groceries, err := l.CreateAccount("Food:Groceries", Expense, "", "USD", openedAt, "0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	cafe, err := l.CreateAccount("Food:Cafe", Expense, "", "USD", openedAt, "0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, d := range []struct {
+		dst  ID
+		date time.Time
+		v    string
+	}{
+		{groceries.ID, time.Date(2024, time.January, 10, 12, 0, 0, 0, time.UTC), "100"},
+		{cafe.ID, time.Date(2024, time.January, 20, 12, 0, 0, 0, time.UTC), "50"},
+		{groceries.ID, time.Date(2024, time.March, 5, 12, 0, 0, 0, time.UTC), "200"},
+	} {
+		if _, err := l.CreateTransaction(bank.ID, d.dst, d.date, d.v, ""); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	values := func(points []SeriesPoint) (s []string) {
+		for _, p := range points {
+			s = append(s, p.Value.String())
+		}
+		return
+	}
+
+	jan1 := time.Date(2024, time.January, 1, 0, 0, 0, 0, time.UTC)
+	apr15 := time.Date(2024, time.April, 15, 0, 0, 0, 0, time.UTC)
+
+	points, err := l.BalanceSeries(bank.ID, Monthly, jan1, apr15)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := values(points), []string{"850", "850", "650", "650"}; !slices.Equal(got, want) {
+		t.Errorf("expected monthly balances %v, got: %v", want, got)
+	}
+	if end := points[3].End; !end.Equal(apr15.AddDate(0, 0, 1)) {
+		t.Errorf("expected the last period to be cut at the end of range, got: %s", end)
+	}
+
+	points, err = l.BalanceSeries(bank.ID, Weekly, jan1.AddDate(0, 0, 2), jan1.AddDate(0, 0, 13))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := values(points), []string{"1000", "900"}; !slices.Equal(got, want) || !points[0].Start.Equal(jan1) {
+		t.Errorf("expected weekly balances %v from Monday, got: %v from %s", want, got, points[0].Start)
+	}
+
+	points, err = l.BalanceSeries(bank.ID, Daily, jan1, jan1.AddDate(0, 1, -1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(points) != 31 || points[9].Value.String() != "900" || points[8].Value.String() != "1000" {
+		t.Errorf("unexpected daily balances: %v", values(points))
+	}
+
+	points, err = l.SubtreeSeries("Expense:Food", "USD", Monthly, jan1, apr15)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := values(points), []string{"150", "150", "350", "350"}; !slices.Equal(got, want) {
+		t.Errorf("expected monthly food expenses %v, got: %v", want, got)
+	}
+
+	points, err = l.NetWorthSeries("USD", Monthly, jan1, apr15)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 850 USD - 100 EUR (200 USD)
+	if got, want := values(points), []string{"650", "650", "450", "450"}; !slices.Equal(got, want) {
+		t.Errorf("expected monthly net worth %v, got: %v", want, got)
+	}
+
+	t.Run("derived", func(t *testing.T) {
+		if err := l.SetBalanceMode(DerivedBalances); err != nil {
+			t.Fatal(err)
+		}
+		defer l.SetBalanceMode(JournalBalances)
+
+		points, err := l.BalanceSeries(bank.ID, Monthly, jan1, apr15)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got, want := values(points), []string{"850", "850", "650", "650"}; !slices.Equal(got, want) {
+			t.Errorf("expected monthly balances %v, got: %v", want, got)
+		}
+
+		points, err = l.NetWorthSeries("USD", Monthly, jan1, apr15)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got, want := values(points), []string{"650", "650", "450", "450"}; !slices.Equal(got, want) {
+			t.Errorf("expected monthly net worth %v, got: %v", want, got)
+		}
+	})
+
+	if _, err := l.BalanceSeries(bank.ID, 42, jan1, apr15); err == nil {
+		t.Error("error expected for wrong frequency, nil found")
+	}
+	if _, err := l.BalanceSeries(bank.ID, Daily, apr15, jan1); err == nil {
+		t.Error("error expected for wrong range, nil found")
+	}
+}
+
+func BenchmarkBalanceSeries(b *testing.B) {
+	l := CreateLedger(CreateAccountRegistry(), CreateBalanceRegistry(), CreateTransactionRegistry(),
+		CreateCurrencyRegistry(), CreateTagRegistry(), CreateTagsMapRegistry())
+
+	openedAt := time.Date(2015, time.January, 1, 0, 0, 0, 0, time.UTC)
+	bank, _ := l.CreateAccount("Bank", Asset, "", "USD", openedAt, "1000000")
+	shop, _ := l.CreateAccount("Shop", Expense, "", "USD", openedAt, "0")
+	for i := range 10 * 365 {
+		if _, err := l.CreateTransaction(bank.ID, shop.ID, openedAt.AddDate(0, 0, i).Add(time.Hour), "1", ""); err != nil {
+			b.Fatal(err)
+		}
+	}
+
+	for _, mode := range []struct {
+		name string
+		mode int
+	}{{"journal", JournalBalances}, {"derived", DerivedBalances}} {
+		b.Run(mode.name, func(b *testing.B) {
+			if err := l.SetBalanceMode(mode.mode); err != nil {
+				b.Fatal(err)
+			}
+			for range b.N {
+				if _, err := l.BalanceSeries(bank.ID, Daily, openedAt, openedAt.AddDate(10, 0, 0)); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
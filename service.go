@@ -3,6 +3,7 @@ package miser
 import (
 	"errors"
 	"fmt"
+	"slices"
 	"strings"
 	"time"
 )
@@ -14,12 +15,41 @@ type Ledger struct {
 	cr *CurrencyRegistry
 	tg *TagRegistry
 	tm *TagMapRegistry
+	rt *RateRegistry
+	rc *ReconciliationRegistry
+	sc *ScheduleRegistry
+	sq *SavedQueryRegistry
+	as *AssertionRegistry
+
+	analysis AnalysisOptions
+	ad       *AnomalyDetector
+	mode     int // JournalBalances or DerivedBalances
+	bc       *balanceCache
 }
 
 func CreateLedger(ar *AccountRegistry, br *BalanceRegistry, tr *TransactionRegistry, cr *CurrencyRegistry, tg *TagRegistry, tm *TagMapRegistry) *Ledger {
-	return &Ledger{ar: ar, tr: tr, br: br, cr: cr, tg: tg, tm: tm}
+	return &Ledger{ar: ar, tr: tr, br: br, cr: cr, tg: tg, tm: tm,
+		rt: CreateRateRegistry(), rc: CreateReconciliationRegistry(),
+		sc: CreateScheduleRegistry(), sq: CreateSavedQueryRegistry(), as: CreateAssertionRegistry(),
+		analysis: DefaultAnalysisOptions(), ad: CreateAnomalyDetector(DefaultAnomalyOptions()),
+		bc: createBalanceCache()}
 }
 
+// Registry of exchange rates, use it to load, save or import rates.
+func (l *Ledger) Rates() *RateRegistry { return l.rt }
+
+// Registry of finished reconciliations.
+func (l *Ledger) Reconciliations() *ReconciliationRegistry { return l.rc }
+
+// Registry of recurring transaction definitions.
+func (l *Ledger) Schedules() *ScheduleRegistry { return l.sc }
+
+// Registry of saved named queries.
+func (l *Ledger) Queries() *SavedQueryRegistry { return l.sq }
+
+// Registry of balance assertions.
+func (l *Ledger) Assertions() *AssertionRegistry { return l.as }
+
 // Save all queued data, sync it to disk.
 func (l *Ledger) Save() {
 	l.tr.Save()
@@ -27,74 +57,347 @@ func (l *Ledger) Save() {
 	l.ar.Save()
 	l.tg.Save()
 	l.tm.Save()
+	l.rt.Save()
+	l.cr.Save()
+	l.rc.Save()
+	l.sc.Save()
+	l.sq.Save()
+	l.as.Save()
 }
 
-func (l *Ledger) CreateInitialTransaction(accID ID, openedAt time.Time, v int64) *Transaction {
+func (l *Ledger) CreateInitialTransaction(accID ID, openedAt time.Time, v Amount) *Transaction {
 	transa := Transaction{
 		ID: CreateID(), Source: accID, Dest: accID, Time: openedAt,
-		Value: v, Text: "Initial balance"}
+		Value: v, Text: "Initial balance", Seq: l.tr.NextSeq()}
 	l.tr.Add(transa)
 	l.tr.AddQueued(transa)
 
 	return &transa
 }
 
-func (l *Ledger) CreateTransaction(src, dst ID, t time.Time, v float64, txt string) (*Transaction, error) {
-	if v <= 0 {
-		return nil, errors.New("transaction value should be greater zero")
+// Create transaction, spending which is unusual for its expense account
+// or goes to never seen counterpart is tagged as Unexpected.
+func (l *Ledger) CreateTransaction(src, dst ID, t time.Time, v string, txt string) (*Transaction, error) {
+	l.refreshSpendings()
+	transa, err := l.postTransaction(src, dst, t, v, txt)
+	if err != nil {
+		return nil, err
 	}
+	l.inspectSpending(transa, true)
+	return transa, nil
+}
 
-	if t.IsZero() {
-		return nil, errors.New("zero time of transaction is not allowed")
+func (l *Ledger) postTransaction(src, dst ID, t time.Time, v string, txt string) (*Transaction, error) {
+	srcAcc, dstAcc, value, err := l.validateTransaction(nil, src, dst, t, v)
+	if err != nil {
+		return nil, err
 	}
 
-	srcAcc := l.ar.Get(src)
-	if srcAcc == nil {
-		return nil, errors.New("src account not found")
+	transa := Transaction{
+		ID:     CreateID(),
+		Source: src,
+		Dest:   dst,
+		Time:   t,
+		Value:  value,
+		Text:   EncryptedString(txt),
+	}
+	if err := l.checkAssertions(l.transactionChanges(&transa, false)...); err != nil {
+		return nil, err
 	}
 
-	dstAcc := l.ar.Get(dst)
-	if dstAcc == nil {
-		return nil, errors.New("dst account not found")
+	transa.Seq = l.tr.NextSeq()
+	l.tr.Add(transa)
+	l.tr.AddQueued(transa)
+
+	if err := l.postBalances(&transa, srcAcc, dstAcc); err != nil {
+		return nil, err
 	}
 
-	if t.Before(srcAcc.OpenedAt) || t.Before(dstAcc.OpenedAt) {
-		return nil, errors.New("transaction cannot be before the account is opened")
+	return &transa, nil
+}
+
+// Update a posted transaction: a new version of it is written and balances
+// of old and new accounts are recomputed from the earliest affected point onward.
+func (l *Ledger) UpdateTransaction(trID, src, dst ID, t time.Time, v string, txt string) (*Transaction, error) {
+	old := l.tr.Get(trID)
+	if old == nil {
+		return nil, errors.New("transaction not found")
 	}
 
-	value := int64(v * Million)
+	if old.IsInitial() {
+		return nil, errors.New("initial transaction cannot be updated")
+	}
 
-	b := l.AccountBalance(src)
-	if b.Value < value {
-		return nil, errors.New("you cannot trasfer more money than you have")
+	if old.Deleted {
+		return nil, errors.New("deleted transaction cannot be updated")
 	}
 
-	if srcAcc.Type == dstAcc.Type {
-		return nil, errors.New("cannot be transferred to same type of account")
+	if old.Voids != "" {
+		return nil, errors.New("reversing entry cannot be updated")
+	}
+
+	if l.tr.VoidedBy(trID) != nil {
+		return nil, errors.New("voided transaction cannot be updated")
+	}
+
+	if old.State == Cleared {
+		return nil, ErrCleared
+	}
+
+	_, _, value, err := l.validateTransaction(old, src, dst, t, v)
+	if err != nil {
+		return nil, err
 	}
 
 	transa := Transaction{
-		ID:     CreateID(),
+		ID:     old.ID,
 		Source: src,
 		Dest:   dst,
 		Time:   t,
 		Value:  value,
 		Text:   EncryptedString(txt),
+		State:  old.State,
+		Seq:    old.Seq,
 	}
+
+	changes := append(l.transactionChanges(old, true), l.transactionChanges(&transa, false)...)
+	if err := l.checkAssertions(changes...); err != nil {
+		return nil, err
+	}
+
 	l.tr.Add(transa)
 	l.tr.AddQueued(transa)
 
-	if err := l.UpdateBalance(src, transa.ID, string(srcAcc.Type), Credit, t, value); err != nil {
+	// the balances of accounts which are not a part of transaction anymore are obsolete:
+	for _, accID := range []ID{old.Source, old.Dest} {
+		if l.mode == JournalBalances && accID != src && accID != dst {
+			l.DeleteBalance(accID, trID)
+		}
+	}
+
+	from := old.Time
+	if t.Before(from) {
+		from = t
+	}
+	for _, accID := range uniqueIDs(old.Source, old.Dest, src, dst) {
+		if err := l.rebalance(accID, from); err != nil {
+			return nil, err
+		}
+	}
+	l.untagItem(Unexpected, trID) // the check does not apply to the new version
+	l.ad.invalidate()
+
+	return &transa, nil
+}
+
+// Soft delete a posted transaction, the balances of both accounts
+// are recomputed from the time of transaction onward.
+func (l *Ledger) DeleteTransaction(trID ID) error {
+	transa := l.tr.Get(trID)
+	if transa == nil {
+		return errors.New("transaction not found")
+	}
+
+	if transa.IsInitial() {
+		return errors.New("initial transaction cannot be deleted")
+	}
+
+	if transa.Deleted {
+		return errors.New("transaction is already deleted")
+	}
+
+	if transa.Voids != "" {
+		return errors.New("reversing entry cannot be deleted")
+	}
+
+	if l.tr.VoidedBy(trID) != nil {
+		return errors.New("voided transaction cannot be deleted")
+	}
+
+	if transa.State == Cleared {
+		return ErrCleared
+	}
+
+	if err := l.checkAssertions(l.transactionChanges(transa, true)...); err != nil {
+		return err
+	}
+
+	transa.Deleted = true
+	l.tr.Add(*transa)
+	l.tr.AddQueued(*transa)
+
+	for _, accID := range uniqueIDs(transa.Source, transa.Dest) {
+		if l.mode == JournalBalances {
+			l.DeleteBalance(accID, trID)
+		}
+		if err := l.rebalance(accID, transa.Time); err != nil {
+			return err
+		}
+	}
+	l.ad.invalidate()
+	return nil
+}
+
+// Void a posted transaction: post a reversing entry linked to it at given time,
+// the original transaction stays untouched (useful if history must be immutable).
+// The reversing entry is not limited by the balance of source account.
+func (l *Ledger) VoidTransaction(trID ID, t time.Time) (*Transaction, error) {
+	orig := l.tr.Get(trID)
+	if orig == nil {
+		return nil, errors.New("transaction not found")
+	}
+
+	if orig.IsInitial() {
+		return nil, errors.New("initial transaction cannot be voided")
+	}
+
+	if orig.Deleted {
+		return nil, errors.New("deleted transaction cannot be voided")
+	}
+
+	if orig.Voids != "" {
+		return nil, errors.New("reversing entry cannot be voided")
+	}
+
+	if l.tr.VoidedBy(trID) != nil {
+		return nil, errors.New("transaction is already voided")
+	}
+
+	if !t.After(orig.Time) {
+		return nil, errors.New("reversing entry should be after the voided transaction")
+	}
+
+	srcAcc, dstAcc := l.ar.Get(orig.Dest), l.ar.Get(orig.Source)
+	if srcAcc == nil || dstAcc == nil {
+		return nil, errors.New("account of transaction not found")
+	}
+
+	transa := Transaction{
+		ID:     CreateID(),
+		Source: orig.Dest,
+		Dest:   orig.Source,
+		Time:   t,
+		Value:  orig.Value,
+		Text:   "Void: " + orig.Text,
+		Voids:  orig.ID,
+	}
+	if err := l.checkAssertions(l.transactionChanges(&transa, false)...); err != nil {
 		return nil, err
 	}
-	if err := l.UpdateBalance(dst, transa.ID, string(dstAcc.Type), Debit, t, value); err != nil {
+
+	transa.Seq = l.tr.NextSeq()
+	l.tr.Add(transa)
+	l.tr.AddQueued(transa)
+
+	if err := l.postBalances(&transa, srcAcc, dstAcc); err != nil {
 		return nil, err
 	}
+	l.untagItem(Unexpected, trID)
+	l.ad.invalidate()
 
 	return &transa, nil
 }
 
-func (l *Ledger) CreateAccount(n, t, d, c string, openedAt time.Time, initBalance float64) (*Account, error) {
+// Reorder transactions recorded at the same time (e.g. date-only imports):
+// they get their sequences in the given order, then balances of their accounts are recomputed.
+// The initial transaction always stays the first one of its account.
+func (l *Ledger) ReorderTransactions(ids ...ID) error {
+	var trs []Transaction
+	for _, id := range ids {
+		t := l.tr.Get(id)
+		if t == nil || t.Deleted {
+			return fmt.Errorf("transaction not found: %s", id)
+		}
+		if t.IsInitial() {
+			return errors.New("initial transaction cannot be reordered")
+		}
+		if len(trs) > 0 && !t.Time.Equal(trs[0].Time) {
+			return errors.New("only transactions of the same time could be reordered")
+		}
+		if slices.ContainsFunc(trs, func(u Transaction) bool { return u.ID == t.ID }) {
+			return fmt.Errorf("duplicate transaction: %s", id)
+		}
+		trs = append(trs, *t)
+	}
+	if len(trs) < 2 {
+		return nil
+	}
+
+	seqs := make([]uint64, len(trs))
+	for i, t := range trs {
+		seqs[i] = t.Seq
+	}
+	slices.Sort(seqs)
+
+	var accounts []ID
+	for i, t := range trs {
+		t.Seq = seqs[i]
+		l.tr.Add(t)
+		l.tr.AddQueued(t)
+		accounts = append(accounts, t.Source, t.Dest)
+	}
+
+	for _, accID := range uniqueIDs(accounts...) {
+		if err := l.rebalance(accID, trs[0].Time); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Validate transaction data, the old version of transaction is given
+// in case of update (its effect is excluded from the source account balance).
+func (l *Ledger) validateTransaction(old *Transaction, src, dst ID, t time.Time, v string) (srcAcc, dstAcc *Account, value Amount, err error) {
+	if t.IsZero() {
+		return nil, nil, value, errors.New("zero time of transaction is not allowed")
+	}
+
+	srcAcc = l.ar.Get(src)
+	if srcAcc == nil {
+		return nil, nil, value, errors.New("src account not found")
+	}
+
+	dstAcc = l.ar.Get(dst)
+	if dstAcc == nil {
+		return nil, nil, value, errors.New("dst account not found")
+	}
+
+	if t.Before(srcAcc.OpenedAt) || t.Before(dstAcc.OpenedAt) {
+		return nil, nil, value, errors.New("transaction cannot be before the account is opened")
+	}
+
+	value, err = l.parseAmount(srcAcc, v)
+	if err != nil {
+		return nil, nil, value, err
+	}
+	if value.Sign() <= 0 {
+		return nil, nil, value, errors.New("transaction value should be greater zero")
+	}
+
+	var available Amount
+	if b := l.AccountBalance(src); b != nil {
+		available = b.Value
+	}
+	if old != nil {
+		if old.Source == src {
+			available = available.Sub(effect(string(srcAcc.Type), Credit, old.Value))
+		}
+		if old.Dest == src {
+			available = available.Sub(effect(string(srcAcc.Type), Debit, old.Value))
+		}
+	}
+	if available.Cmp(value) < 0 {
+		return nil, nil, value, errors.New("you cannot trasfer more money than you have")
+	}
+
+	if srcAcc.Type == dstAcc.Type {
+		return nil, nil, value, errors.New("cannot be transferred to same type of account")
+	}
+
+	return srcAcc, dstAcc, value, nil
+}
+
+func (l *Ledger) CreateAccount(n, t, d, c string, openedAt time.Time, initBalance string) (*Account, error) {
 	n = strings.TrimSpace(n)
 	if n == "" {
 		return nil, errors.New("name of account is blank")
@@ -104,10 +407,16 @@ func (l *Ledger) CreateAccount(n, t, d, c string, openedAt time.Time, initBalanc
 		return nil, fmt.Errorf("wrong type of account: %s", t)
 	}
 
-	if cur := l.cr.Get(c); cur == nil {
+	cur := l.cr.Get(c)
+	if cur == nil {
 		return nil, fmt.Errorf("currency %q is not supproted", c)
 	}
 
+	v, err := cur.Parse(initBalance)
+	if err != nil {
+		return nil, err
+	}
+
 	acc := Account{
 		ID:       CreateID(),
 		Name:     EncryptedString(n),
@@ -121,29 +430,57 @@ func (l *Ledger) CreateAccount(n, t, d, c string, openedAt time.Time, initBalanc
 	l.ar.AddQueued(acc)
 
 	// create initial transaction
-	v := int64(initBalance * Million)
 	transa := l.CreateInitialTransaction(acc.ID, openedAt, v)
-	l.CreateBalance(acc.ID, transa.ID, v)
+	if l.mode == JournalBalances {
+		l.CreateBalance(acc.ID, transa.ID, v)
+	}
 
 	// tag transaction as initial
-	tag := l.tg.GetByName(Initial)
+	l.tagItem(Initial, transa.ID)
+
+	return &acc, nil
+}
+
+// Tag item by tag of given name, the tag is created if it does not exist.
+// Returns false if the item is already tagged.
+func (l *Ledger) tagItem(name string, itemID ID) bool {
+	tag := l.tg.GetByName(name)
 	if tag == nil {
-		tag = l.tg.Create(Initial)
+		tag = l.tg.Create(name)
+	}
+	if l.tm.Has(tag.ID, itemID) {
+		return false
 	}
-	l.tm.Create(tag.ID, transa.ID)
+	l.tm.Create(tag.ID, itemID)
+	return true
+}
 
-	return &acc, nil
+// Remove the tag of item, it returns false if the item is not tagged by it.
+func (l *Ledger) untagItem(name string, itemID ID) bool {
+	tag := l.tg.GetByName(name)
+	if tag == nil || !l.tm.Has(tag.ID, itemID) {
+		return false
+	}
+	l.tm.Delete(tag.ID, itemID)
+	return true
 }
 
-func (l *Ledger) CreateBalance(accID, trID ID, value int64) *Balance {
+func (l *Ledger) CreateBalance(accID, trID ID, value Amount) *Balance {
 	b := Balance{Account: accID, Transaction: trID, Value: value}
 	l.br.Add(b)
 	l.br.AddQueued(b)
 	return &b
 }
 
-// Credit - source, Debit - destination
-func (l *Ledger) UpdateBalance(accID, trID ID, accType string, operType int, trTime time.Time, value int64) error {
+// Mark balance of account transaction as deleted.
+func (l *Ledger) DeleteBalance(accID, trID ID) {
+	b := Balance{Account: accID, Transaction: trID, Deleted: true}
+	l.br.Add(b)
+	l.br.AddQueued(b)
+}
+
+// Effect of transaction value on balance of account of given type.
+func effect(accType string, operType int, value Amount) Amount {
 	// Account Type  | Effect on Account Balance
 	// ------------------------------------------
 	// --------------|    Debit     |   Credit
@@ -160,15 +497,85 @@ func (l *Ledger) UpdateBalance(accID, trID ID, accType string, operType int, trT
 	switch operType {
 	case Credit:
 		if accType == Asset || accType == Expense {
-			value = -value
+			return value.Neg()
 		}
 	case Debit:
 		if accType == Liability || accType == Equity || accType == Income {
-			value = -value
+			return value.Neg()
 		}
 	}
+	return value
+}
+
+// Recompute balances of account transactions from given time onward,
+// new versions of balances are created only for changed ones.
+func (l *Ledger) rebalance(accID ID, from time.Time) error {
+	if l.mode != JournalBalances {
+		return nil // derived balances are recomputed on demand
+	}
 
-	t := l.tr.FirstBefore(accID, trTime)
+	acc := l.ar.Get(accID)
+	if acc == nil {
+		return fmt.Errorf("account not found, account ID: %s", accID)
+	}
+
+	var value Amount
+	if t := l.tr.FirstBefore(accID, from); t != nil {
+		b := l.br.TransactionBalance(accID, t.ID)
+		if b == nil {
+			return fmt.Errorf("balance not found, transaction ID: %s, account ID: %s", t.ID, accID)
+		}
+		value = b.Value
+	}
+
+	for _, t := range l.tr.Since(accID, from) {
+		value = runningBalance(acc, value, t)
+
+		if b := l.br.TransactionBalance(accID, t.ID); b == nil || !b.Value.Equal(value) {
+			l.CreateBalance(accID, t.ID, value)
+		}
+	}
+	return nil
+}
+
+// Balance of account after transaction given the balance before it.
+func runningBalance(acc *Account, value Amount, t Transaction) Amount {
+	switch {
+	case t.IsInitial():
+		return t.Value
+	case t.Source == acc.ID:
+		return value.Add(effect(string(acc.Type), Credit, t.Value))
+	default:
+		return value.Add(effect(string(acc.Type), Debit, t.Value))
+	}
+}
+
+// Write balances of accounts of a new transaction to the balance journal.
+func (l *Ledger) postBalances(t *Transaction, srcAcc, dstAcc *Account) error {
+	if l.mode != JournalBalances {
+		return nil
+	}
+	if err := l.UpdateBalance(t.Source, t.ID, string(srcAcc.Type), Credit, t.Time, t.Value); err != nil {
+		return err
+	}
+	return l.UpdateBalance(t.Dest, t.ID, string(dstAcc.Type), Debit, t.Time, t.Value)
+}
+
+func uniqueIDs(ids ...ID) (unique []ID) {
+	for _, id := range ids {
+		if !slices.Contains(unique, id) {
+			unique = append(unique, id)
+		}
+	}
+	return
+}
+
+// Credit - source, Debit - destination
+func (l *Ledger) UpdateBalance(accID, trID ID, accType string, operType int, trTime time.Time, value Amount) error {
+	value = effect(accType, operType, value)
+
+	// the previous transaction by position: the same time ones are ordered by sequence
+	t := l.tr.Prev(accID, trID)
 	if t == nil {
 		return fmt.Errorf("transaction not found, before %s, account ID: %s", trTime, accID)
 	}
@@ -178,7 +585,7 @@ func (l *Ledger) UpdateBalance(accID, trID ID, accType string, operType int, trT
 		return fmt.Errorf("balance not found, transaction ID: %s, account ID: %s", t.ID, accID)
 	}
 
-	l.CreateBalance(accID, trID, b.Value+value)
+	l.CreateBalance(accID, trID, b.Value.Add(value))
 
 	// rebalance in case if the current transaction was in the middle of history:
 	//   t b      t b
@@ -188,31 +595,39 @@ func (l *Ledger) UpdateBalance(accID, trID ID, accType string, operType int, trT
 	// -13 117  -20 125
 	//          -13 112
 	// fix = -5 (value of middle transaction)
-	for _, transa := range l.tr.AllAfter(accID, trTime) {
+	for _, transa := range l.tr.Next(accID, trID) {
 		oldBalance := l.br.TransactionBalance(accID, transa.ID)
 		if oldBalance != nil {
-			l.CreateBalance(accID, transa.ID, oldBalance.Value+value)
+			l.CreateBalance(accID, transa.ID, oldBalance.Value.Add(value))
 		}
 	}
 
 	return nil
 }
 
+// Parse decimal string amount in currency of account.
+func (l *Ledger) parseAmount(acc *Account, s string) (Amount, error) {
+	c := l.cr.Get(string(acc.Cur))
+	if c == nil {
+		return ParseAmount(s, AmountDigits)
+	}
+	return c.Parse(s)
+}
+
 func (l *Ledger) AmountTransaction(t *Transaction) string {
 	acc := l.ar.Get(t.Source)
 	c := l.cr.Get(string(acc.Cur))
 	if c != nil {
-		return fmt.Sprintf("%s %.2f", c.Sign, float64(t.Value)/Million)
+		return c.Format(t.Value)
 	}
-	return fmt.Sprintf("%.2f", float64(t.Value)/Million)
-
+	return t.Value.String()
 }
 
 // Account balance: balance at time of last transaction.
 func (l *Ledger) AccountBalance(accID ID) *Balance {
 	lastTransa := l.tr.Last(accID)
 	if lastTransa != nil {
-		b := l.br.TransactionBalance(accID, lastTransa.ID)
+		b := l.TransactionBalance(accID, lastTransa.ID)
 		if b != nil {
 			return b
 		}
@@ -220,11 +635,106 @@ func (l *Ledger) AccountBalance(accID ID) *Balance {
 	return nil
 }
 
+// Account balance at given time: the balance after the last transaction of account before
+// the time (or at the time, if inclusive). It is zero before the account is opened.
+func (l *Ledger) AccountBalanceAt(accID ID, t time.Time, inclusive bool) (Amount, error) {
+	acc := l.ar.Get(accID)
+	if acc == nil {
+		return Amount{}, errors.New("account not found")
+	}
+
+	end := t
+	if inclusive {
+		end = t.Add(time.Nanosecond) // the smallest step of time
+	}
+
+	if !end.After(acc.OpenedAt) {
+		return Amount{}, nil
+	}
+
+	last := l.tr.FirstBefore(accID, end)
+	if last == nil {
+		return Amount{}, nil
+	}
+
+	b := l.TransactionBalance(accID, last.ID)
+	if b == nil {
+		return Amount{}, fmt.Errorf("balance not found, transaction ID: %s, account ID: %s", last.ID, accID)
+	}
+	return b.Value, nil
+}
+
+// Balances of many accounts at given time (see AccountBalanceAt).
+func (l *Ledger) AccountBalancesAt(ids []ID, t time.Time, inclusive bool) (map[ID]Amount, error) {
+	balances := make(map[ID]Amount, len(ids))
+	for _, accID := range ids {
+		v, err := l.AccountBalanceAt(accID, t, inclusive)
+		if err != nil {
+			return nil, err
+		}
+		balances[accID] = v
+	}
+	return balances, nil
+}
+
 // Account amount.
 func (l *Ledger) AccountAmount(accID ID) float64 {
 	b := l.AccountBalance(accID)
 	if b == nil {
 		return 0
 	}
-	return float64(b.Value) / Million
+	return b.Value.Float()
+}
+
+// Convert value of one currency to another using the rate known at date,
+// the result is rounded to minor units of target currency.
+func (l *Ledger) Convert(v Amount, from, to string, date time.Time) (Amount, error) {
+	if from == to {
+		return v, nil
+	}
+	v, err := l.rt.Convert(v, from, to, date)
+	if err != nil {
+		return Amount{}, err
+	}
+	if c := l.cr.Get(to); c != nil {
+		v = v.Round(c.Digits())
+	}
+	return v, nil
+}
+
+// Account balance at the end of date converted to given currency at the date.
+func (l *Ledger) AccountBalanceIn(accID ID, cur string, date time.Time) (Amount, error) {
+	acc := l.ar.Get(accID)
+	if acc == nil {
+		return Amount{}, errors.New("account not found")
+	}
+
+	v, err := l.AccountBalanceAt(accID, nextDay(date), false)
+	if err != nil {
+		return Amount{}, err
+	}
+	return l.Convert(v, string(acc.Cur), cur, date)
+}
+
+// Net worth: assets minus liabilities in given currency at the end of date.
+func (l *Ledger) NetWorth(cur string, date time.Time) (Amount, error) {
+	if l.cr.Get(cur) == nil {
+		return Amount{}, fmt.Errorf("currency %q is not supproted", cur)
+	}
+
+	var total Amount
+	for _, acc := range l.ar.List() {
+		if acc.Deleted || (acc.Type != Asset && acc.Type != Liability) {
+			continue
+		}
+		v, err := l.AccountBalanceIn(acc.ID, cur, date)
+		if err != nil {
+			return Amount{}, err
+		}
+		if acc.Type == Liability {
+			v = v.Neg()
+		}
+		total = total.Add(v)
+	}
+	return total, nil
 }
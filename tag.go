@@ -6,14 +6,25 @@ import (
 
 // Special system tag names:
 const (
-	Initial    = "Initial"
+	Initial = "Initial"
+
+	// set to unusual spendings by anomaly detection (see Ledger.Unexpected):
 	Unexpected = "Unexpected"
 
-	// todo add analysis of transactions during the load and mark some transactions as:
+	// set by analysis of transactions during the load (see Ledger.Analyze):
 	OverAverage = "OverAverage"
 	Periodic    = "Periodic"
 )
 
+// Check if tag of given name is set by the ledger itself, not by user.
+func isSystemTag(name string) bool {
+	switch name {
+	case Initial, Unexpected, OverAverage, Periodic:
+		return true
+	}
+	return false
+}
+
 // Tag ties name of a tag and its id, nothing more.
 // For tagging object use TagMap.
 type Tag struct {
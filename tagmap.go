@@ -26,6 +26,22 @@ func (tm *TagMapRegistry) Create(tagID, itemID ID) {
 	tm.AddQueued(t)
 }
 
+// Remove the tag of item.
+func (tm *TagMapRegistry) Delete(tagID, itemID ID) {
+	t := TagMap{Tag: tagID, Item: itemID, Deleted: true}
+	tm.Add(t)
+	tm.AddQueued(t)
+}
+
+// Check if item is tagged by the tag.
+func (tm *TagMapRegistry) Has(tagID, itemID ID) bool {
+	tm.RLock()
+	defer tm.RUnlock()
+
+	t, ok := tm.items[TagMap{Tag: tagID, Item: itemID}.Key()]
+	return ok && !t.Deleted
+}
+
 func (tm *TagMapRegistry) Add(t TagMap) int {
 	tm.Lock()
 	defer tm.Unlock()
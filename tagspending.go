@@ -0,0 +1,195 @@
+package miser
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"math/big"
+	"slices"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+// Modes of spending of transactions tagged by several tags.
+const (
+	CountEachTag   = iota // the whole value is counted in each tag, shares of tags may sum over 100%
+	SplitAmongTags        // the value is split evenly among tags (see Amount.Split)
+)
+
+// TagSpending is a report of spending (postings of Expense accounts) per tag per month.
+// Only tags set by user are taken into account, transactions without them go to the Untagged line.
+// Reversing entries are counted under tags of the voided transactions.
+type TagSpending struct {
+	Currency string
+	Mode     int         // CountEachTag or SplitAmongTags
+	Months   []time.Time // starts of months
+	Lines    []TagSpendingLine
+	Totals   []Amount // total spending per month, every transaction is counted once
+	Total    Amount
+}
+
+// TagSpendingLine is a spending of tag: values per month, their changes and shares of total spending.
+type TagSpendingLine struct {
+	Tag            string
+	Values         []Amount
+	Changes        []Amount  // from the previous month, the first one is from the month before the report
+	ChangePercents []float64 // 0 if spending of the previous month is zero
+	Shares         []float64 // percent of total spending of month
+	Total          Amount
+	Share          float64 // percent of total spending
+}
+
+// Spending per tag per month of whole months from..to in given currency,
+// the values are converted at time of transactions.
+func (l *Ledger) TagSpending(from, to time.Time, cur string, mode int) (*TagSpending, error) {
+	c := l.cr.Get(cur)
+	if c == nil {
+		return nil, fmt.Errorf("currency %q is not supproted", cur)
+	}
+	if mode != CountEachTag && mode != SplitAmongTags {
+		return nil, fmt.Errorf("wrong mode of tag spending: %d", mode)
+	}
+	if to.Before(from) {
+		return nil, errors.New("end of period cannot be before its start")
+	}
+
+	// the month before the report is needed for change of the first month:
+	start := periodStart(Monthly, from).AddDate(0, -1, 0)
+	end := nextPeriod(Monthly, periodStart(Monthly, to))
+	monthIndex := func(t time.Time) int {
+		return (t.Year()-start.Year())*12 + int(t.Month()-start.Month())
+	}
+	n := monthIndex(end)
+
+	s := &TagSpending{Currency: cur, Mode: mode}
+	for m := start; m.Before(end); m = nextPeriod(Monthly, m) {
+		s.Months = append(s.Months, m)
+	}
+
+	values := make(map[string][]Amount)
+	totals := make([]Amount, n)
+	add := func(tag string, i int, v Amount) {
+		if values[tag] == nil {
+			values[tag] = make([]Amount, n)
+		}
+		values[tag][i] = values[tag][i].Add(v)
+	}
+
+	for _, acc := range l.ar.List() {
+		if acc.Type != Expense {
+			continue
+		}
+		for _, t := range l.tr.Since(acc.ID, start) {
+			if !t.Time.Before(end) {
+				break
+			}
+			if t.IsInitial() {
+				continue
+			}
+
+			operType := Debit
+			if t.Source == acc.ID {
+				operType = Credit // refund
+			}
+			v, err := l.Convert(effect(Expense, operType, t.Value), string(acc.Cur), cur, t.Time)
+			if err != nil {
+				return nil, err
+			}
+
+			i := monthIndex(t.Time.In(start.Location()))
+			totals[i] = totals[i].Add(v)
+
+			tags := slices.DeleteFunc(l.reportTags(t), isSystemTag)
+			switch {
+			case len(tags) == 0:
+				add(Untagged, i, v)
+			case mode == SplitAmongTags:
+				for k, part := range v.Split(len(tags), c.Digits()) {
+					add(tags[k], i, part)
+				}
+			default:
+				for _, tag := range tags {
+					add(tag, i, v)
+				}
+			}
+		}
+	}
+
+	// drop the month before the report:
+	s.Months, s.Totals = s.Months[1:], totals[1:]
+	for _, v := range s.Totals {
+		s.Total = s.Total.Add(v)
+	}
+
+	for tag, v := range values {
+		line := TagSpendingLine{Tag: tag, Values: v[1:]}
+		for i, value := range line.Values {
+			change := value.Sub(v[i])
+			line.Changes = append(line.Changes, change)
+			line.ChangePercents = append(line.ChangePercents, percent(change, v[i]))
+			line.Shares = append(line.Shares, percent(value, s.Totals[i]))
+			line.Total = line.Total.Add(value)
+		}
+		if !slices.ContainsFunc(line.Values, func(a Amount) bool { return !a.IsZero() }) {
+			continue // spending of the month before the report only
+		}
+		line.Share = percent(line.Total, s.Total)
+		s.Lines = append(s.Lines, line)
+	}
+	slices.SortFunc(s.Lines, func(a, b TagSpendingLine) int {
+		if c := b.Total.Cmp(a.Total); c != 0 {
+			return c
+		}
+		return cmp.Compare(a.Tag, b.Tag)
+	})
+	return s, nil
+}
+
+// Percent of a in b, 0 if b is zero.
+func percent(a, b Amount) float64 {
+	if b.IsZero() {
+		return 0
+	}
+	f, _ := new(big.Rat).Mul(new(big.Rat).Quo(a.Rat(), b.Rat()), big.NewRat(100, 1)).Float64()
+	return f
+}
+
+// Render tag spending as text table: values of months with changes and shares, the last column is total.
+func (s *TagSpending) Render(digits int) string {
+	var sb strings.Builder
+	w := tabwriter.NewWriter(&sb, 0, 0, 2, ' ', 0)
+
+	fmt.Fprintf(w, "Spending by tags, %s\n", s.Currency)
+	fmt.Fprint(w, "Tag")
+	for _, m := range s.Months {
+		fmt.Fprintf(w, "\t%s", m.Format("2006-01"))
+	}
+	fmt.Fprintln(w, "\tTotal\t")
+
+	for _, line := range s.Lines {
+		fmt.Fprint(w, line.Tag)
+		for i, v := range line.Values {
+			fmt.Fprintf(w, "\t%s (%+.1f%%, %.1f%%)", v.Format(digits), line.ChangePercents[i], line.Shares[i])
+		}
+		fmt.Fprintf(w, "\t%s (%.1f%%)\t\n", line.Total.Format(digits), line.Share)
+	}
+
+	fmt.Fprint(w, "Total")
+	for _, v := range s.Totals {
+		fmt.Fprintf(w, "\t%s", v.Format(digits))
+	}
+	fmt.Fprintf(w, "\t%s\t\n", s.Total.Format(digits))
+
+	w.Flush()
+	return sb.String()
+}
+
+// Spending per tag per month rendered as text.
+func (l *Ledger) TagSpendingText(from, to time.Time, cur string, mode int) (string, error) {
+	s, err := l.TagSpending(from, to, cur, mode)
+	if err != nil {
+		return "", err
+	}
+	return s.Render(l.reportDigits(cur)), nil
+}
@@ -0,0 +1,203 @@
+package miser
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestTagSpending(t *testing.T) {
+	t.Parallel()
+
+	// Create repositories:
+	ar := CreateAccountRegistry()
+	tr := CreateTransactionRegistry()
+	br := CreateBalanceRegistry()
+	cr := CreateCurrencyRegistry()
+	tg := CreateTagRegistry()
+	tm := CreateTagsMapRegistry()
+
+	// Create service:
+	l := CreateLedger(ar, br, tr, cr, tg, tm)
+
+	openedAt := time.Date(2023, time.December, 1, 9, 0, 0, 0, time.UTC)
+	bank, err := l.CreateAccount("Bank", Asset, "checking", "USD", openedAt, "10000")
+	if err != nil {
+		t.Fatal(err)
+	}
+	groceries, err := l.CreateAccount("Groceries", Expense, "", "USD", openedAt, "0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, d := range []struct {
+		date time.Time
+		v    string
+		tags []string
+	}{
+		{time.Date(2023, time.December, 20, 12, 0, 0, 0, time.UTC), "50", []string{"food"}},
+		{time.Date(2024, time.January, 10, 12, 0, 0, 0, time.UTC), "100", []string{"food"}},
+		{time.Date(2024, time.January, 15, 12, 0, 0, 0, time.UTC), "60", []string{"food", "home"}},
+		{time.Date(2024, time.January, 20, 12, 0, 0, 0, time.UTC), "40", []string{Periodic}},
+		{time.Date(2024, time.February, 5, 12, 0, 0, 0, time.UTC), "80", []string{"food"}},
+		{time.Date(2024, time.March, 3, 12, 0, 0, 0, time.UTC), "20", []string{"home"}},
+		{time.Date(2024, time.April, 3, 12, 0, 0, 0, time.UTC), "1000", []string{"home"}},
+	} {
+		tr, err := l.CreateTransaction(bank.ID, groceries.ID, d.date, d.v, "")
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, name := range d.tags {
+			l.tagItem(name, tr.ID)
+		}
+	}
+
+	from := time.Date(2024, time.January, 15, 0, 0, 0, 0, time.UTC) // whole months are taken
+	to := time.Date(2024, time.March, 31, 0, 0, 0, 0, time.UTC)
+
+	strs := func(values []Amount) (s []string) {
+		for _, v := range values {
+			s = append(s, v.String())
+		}
+		return
+	}
+	percents := func(values []float64) (s []string) {
+		for _, v := range values {
+			s = append(s, fmt.Sprintf("%.1f", v))
+		}
+		return
+	}
+
+	for _, c := range []struct {
+		name string
+		mode int
+		want map[string][]string // values per month
+	}{
+		{"count each tag", CountEachTag, map[string][]string{
+			"food":   {"160", "80", "0"},
+			"home":   {"60", "0", "20"},
+			Untagged: {"40", "0", "0"},
+		}},
+		{"split among tags", SplitAmongTags, map[string][]string{
+			"food":   {"130", "80", "0"},
+			"home":   {"30", "0", "20"},
+			Untagged: {"40", "0", "0"},
+		}},
+	} {
+		t.Run(c.name, func(t *testing.T) {
+			s, err := l.TagSpending(from, to, "USD", c.mode)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var months []string
+			for _, m := range s.Months {
+				months = append(months, m.Format(time.DateOnly))
+			}
+			if want := []string{"2024-01-01", "2024-02-01", "2024-03-01"}; !slices.Equal(months, want) {
+				t.Errorf("expected months %v, got: %v", want, months)
+			}
+			if got, want := strs(s.Totals), []string{"200", "80", "20"}; !slices.Equal(got, want) || s.Total.String() != "300" {
+				t.Errorf("expected totals %v (300), got: %v (%s)", want, got, s.Total)
+			}
+
+			var tags []string
+			var sum Amount
+			for _, line := range s.Lines {
+				tags = append(tags, line.Tag)
+				sum = sum.Add(line.Total)
+				if got := strs(line.Values); !slices.Equal(got, c.want[line.Tag]) {
+					t.Errorf("expected %s %v, got: %v", line.Tag, c.want[line.Tag], got)
+				}
+			}
+			if want := []string{"food", "home", Untagged}; !slices.Equal(tags, want) {
+				t.Errorf("expected tags by total %v, got: %v", want, tags)
+			}
+			if c.mode == SplitAmongTags && !sum.Equal(s.Total) {
+				t.Errorf("expected split tags to sum up to total %s, got: %s", s.Total, sum)
+			}
+		})
+	}
+
+	t.Run("changes and shares", func(t *testing.T) {
+		s, err := l.TagSpending(from, to, "USD", CountEachTag)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		food := s.Lines[0]
+		if got, want := strs(food.Changes), []string{"110", "-80", "-80"}; !slices.Equal(got, want) {
+			t.Errorf("expected changes %v, got: %v", want, got)
+		}
+		if got, want := percents(food.ChangePercents), []string{"220.0", "-50.0", "-100.0"}; !slices.Equal(got, want) {
+			t.Errorf("expected change percents %v, got: %v", want, got)
+		}
+		if got, want := percents(food.Shares), []string{"80.0", "100.0", "0.0"}; !slices.Equal(got, want) {
+			t.Errorf("expected shares %v, got: %v", want, got)
+		}
+		if got := fmt.Sprintf("%.1f", food.Share); got != "80.0" {
+			t.Errorf("expected share of total 80.0, got: %s", got)
+		}
+
+		home := s.Lines[1]
+		if got, want := percents(home.ChangePercents), []string{"0.0", "-100.0", "0.0"}; !slices.Equal(got, want) {
+			t.Errorf("expected change percents from zero to be zero %v, got: %v", want, got)
+		}
+	})
+
+	t.Run("text", func(t *testing.T) {
+		text, err := l.TagSpendingText(from, to, "USD", CountEachTag)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, want := range []string{"2024-01", "160.00 (+220.0%, 80.0%)", Untagged, "300.00"} {
+			if !strings.Contains(text, want) {
+				t.Errorf("expected %q in text:\n%s", want, text)
+			}
+		}
+		if strings.Contains(text, Periodic) {
+			t.Errorf("expected no system tags in text:\n%s", text)
+		}
+	})
+
+	t.Run("voided", func(t *testing.T) {
+		orig, err := l.CreateTransaction(bank.ID, groceries.ID, time.Date(2024, time.March, 10, 12, 0, 0, 0, time.UTC), "25", "")
+		if err != nil {
+			t.Fatal(err)
+		}
+		l.tagItem("food", orig.ID)
+		l.tagItem("home", orig.ID)
+		if _, err := l.VoidTransaction(orig.ID, time.Date(2024, time.March, 12, 12, 0, 0, 0, time.UTC)); err != nil {
+			t.Fatal(err)
+		}
+
+		for _, mode := range []int{CountEachTag, SplitAmongTags} {
+			s, err := l.TagSpending(from, to, "USD", mode)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, line := range s.Lines {
+				if line.Values[2].String() != map[string]string{"food": "0", "home": "20", Untagged: "0"}[line.Tag] {
+					t.Errorf("expected voided spending to be cancelled in March, got: %s %v", line.Tag, strs(line.Values))
+				}
+			}
+			if got := strs(s.Totals); !slices.Equal(got, []string{"200", "80", "20"}) {
+				t.Errorf("expected totals unchanged by voided spending, got: %v", got)
+			}
+		}
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		if _, err := l.TagSpending(from, to, "XXX", CountEachTag); err == nil {
+			t.Error("expected error of unknown currency")
+		}
+		if _, err := l.TagSpending(from, to, "USD", 5); err == nil {
+			t.Error("expected error of wrong mode")
+		}
+		if _, err := l.TagSpending(to, from, "USD", CountEachTag); err == nil {
+			t.Error("expected error of wrong period")
+		}
+	})
+}
@@ -1,10 +1,13 @@
 package miser
 
 import (
+	"cmp"
+	"slices"
 	"sync"
 	"time"
 )
 
+// Amounts were stored in millionths before arbitrary precision.
 const Million = 1_000_000
 
 const (
@@ -17,20 +20,55 @@ type Transaction struct {
 	ID, Source, Dest ID
 	Time             time.Time
 	Text             EncryptedString
-	Value            int64 // in millionths
-	State            int   // one of: Uncleared, Pending, Cleared
+	Value            Amount
+	State            int    // one of: Uncleared, Pending, Cleared
+	Voids            ID     // the transaction reversed by this one (see Ledger.VoidTransaction)
+	Seq              uint64 // insertion sequence, orders transactions of the same time
 	Deleted          bool
 }
 
 func (t *Transaction) IsInitial() bool { return t.Source == t.Dest }
 
+// Compare transactions in the order of ledger: by time, then by insertion sequence.
+func compareTransactions(a, b Transaction) int {
+	return compareEntries(indexEntry{a.Time, a.Seq, a.ID}, indexEntry{b.Time, b.Seq, b.ID})
+}
+
 type TransactionRegistry struct {
-	items  map[ID]Transaction
-	queued map[ID]Transaction
+	items    map[ID]Transaction
+	queued   map[ID]Transaction
+	index    map[ID][]indexEntry // ordered transactions of account, except deleted ones
+	versions map[ID]uint64       // versions of index of account, changed by every its change
+	seq      uint64              // the last insertion sequence
 
 	sync.RWMutex
 }
 
+// Entry of index of transactions, it is ordered by time, then by insertion sequence.
+type indexEntry struct {
+	time time.Time
+	seq  uint64
+	id   ID
+}
+
+func compareEntries(a, b indexEntry) int {
+	if c := a.time.Compare(b.time); c != 0 {
+		return c
+	}
+	if c := cmp.Compare(a.seq, b.seq); c != 0 {
+		return c
+	}
+	return cmp.Compare(a.id, b.id)
+}
+
+// Next insertion sequence, set it to a new transaction.
+func (tr *TransactionRegistry) NextSeq() uint64 {
+	tr.Lock()
+	defer tr.Unlock()
+	tr.seq++
+	return tr.seq
+}
+
 func (tr *TransactionRegistry) List() (transactions []Transaction) {
 	tr.RLock()
 	defer tr.RUnlock()
@@ -43,10 +81,85 @@ func (tr *TransactionRegistry) List() (transactions []Transaction) {
 func (tr *TransactionRegistry) Add(t Transaction) int {
 	tr.Lock()
 	defer tr.Unlock()
+
+	old, ok := tr.items[t.ID]
+	if ok && !old.Deleted {
+		tr.unindex(old)
+	}
+
+	// transactions recorded before the sequence was introduced get it in order of the journal:
+	switch {
+	case t.Seq == 0 && ok:
+		t.Seq = old.Seq
+	case t.Seq == 0:
+		tr.seq++
+		t.Seq = tr.seq
+	default:
+		tr.seq = max(tr.seq, t.Seq)
+	}
+
 	tr.items[t.ID] = t
+	if !t.Deleted {
+		tr.reindex(t)
+	}
 	return 1
 }
 
+// Add transaction to the index of its accounts.
+func (tr *TransactionRegistry) reindex(t Transaction) {
+	e := indexEntry{t.Time, t.Seq, t.ID}
+	for _, accID := range uniqueIDs(t.Source, t.Dest) {
+		entries := tr.index[accID]
+		i, _ := slices.BinarySearchFunc(entries, e, compareEntries)
+		tr.index[accID] = slices.Insert(entries, i, e)
+		tr.versions[accID]++
+	}
+}
+
+// Remove transaction from the index of its accounts.
+func (tr *TransactionRegistry) unindex(t Transaction) {
+	e := indexEntry{t.Time, t.Seq, t.ID}
+	for _, accID := range uniqueIDs(t.Source, t.Dest) {
+		entries := tr.index[accID]
+		if i, found := slices.BinarySearchFunc(entries, e, compareEntries); found {
+			tr.index[accID] = slices.Delete(entries, i, i+1)
+		}
+		tr.versions[accID]++
+	}
+}
+
+// Position of the first transaction of account at given time or after it.
+func (tr *TransactionRegistry) search(accID ID, trTime time.Time) int {
+	i, _ := slices.BinarySearchFunc(tr.index[accID], trTime, func(e indexEntry, t time.Time) int {
+		if e.time.Before(t) {
+			return -1
+		}
+		return 1
+	})
+	return i
+}
+
+// Position of transaction in the index of account, -1 if it is not found.
+func (tr *TransactionRegistry) position(accID ID, trID ID) int {
+	t, ok := tr.items[trID]
+	if !ok {
+		return -1
+	}
+	i, found := slices.BinarySearchFunc(tr.index[accID], indexEntry{t.Time, t.Seq, t.ID}, compareEntries)
+	if !found {
+		return -1
+	}
+	return i
+}
+
+// Transactions of account at positions [i, j) of the index.
+func (tr *TransactionRegistry) slice(accID ID, i, j int) (trs []Transaction) {
+	for _, e := range tr.index[accID][i:j] {
+		trs = append(trs, tr.items[e.id])
+	}
+	return
+}
+
 func (tr *TransactionRegistry) AddQueued(t Transaction) {
 	tr.Lock()
 	defer tr.Unlock()
@@ -62,21 +175,27 @@ func (tr *TransactionRegistry) SyncQueued() (changes []Transaction) {
 	return
 }
 
+func (tr *TransactionRegistry) Get(trID ID) *Transaction {
+	tr.RLock()
+	defer tr.RUnlock()
+	t, ok := tr.items[trID]
+	if ok {
+		return &t
+	}
+	return nil
+}
+
 // Find last transaction.
 func (tr *TransactionRegistry) Last(accID ID) *Transaction {
 	tr.RLock()
 	defer tr.RUnlock()
 
-	var transa *Transaction
-	var mostRecent time.Time
-
-	for _, t := range tr.items {
-		if (t.Source == accID || t.Dest == accID) && t.Time.After(mostRecent) {
-			mostRecent = t.Time
-			transa = &t
-		}
+	entries := tr.index[accID]
+	if len(entries) == 0 {
+		return nil
 	}
-	return transa
+	t := tr.items[entries[len(entries)-1].id]
+	return &t
 }
 
 // Find a transaction of account before given time.
@@ -84,34 +203,84 @@ func (tr *TransactionRegistry) FirstBefore(accID ID, trTime time.Time) *Transact
 	tr.RLock()
 	defer tr.RUnlock()
 
-	var transa *Transaction
+	i := tr.search(accID, trTime)
+	if i == 0 {
+		return nil
+	}
+	t := tr.items[tr.index[accID][i-1].id]
+	return &t
+}
 
-	// the trick: use very big value of duration to be sure that the first will be less
-	minDuration := 24 * time.Hour * 365 * 100 // 100 years
+// Find all transactions of account after given time, sorted by time.
+func (tr *TransactionRegistry) AllAfter(accID ID, trTime time.Time) []Transaction {
+	tr.RLock()
+	defer tr.RUnlock()
 
-	for _, t := range tr.items {
-		if (t.Source == accID || t.Dest == accID) && t.Time.Before(trTime) {
-			min := trTime.Sub(t.Time)
-			if min < minDuration {
-				minDuration = min
-				transa = &t
-			}
-		}
+	i := tr.search(accID, trTime)
+	entries := tr.index[accID]
+	for i < len(entries) && entries[i].time.Equal(trTime) {
+		i++
 	}
-	return transa
+	return tr.slice(accID, i, len(entries))
 }
 
-// Find all transactions of account after given time.
-func (tr *TransactionRegistry) AllAfter(accID ID, trTime time.Time) (trs []Transaction) {
+// Version of index of account transactions, it is changed by every change of them.
+func (tr *TransactionRegistry) version(accID ID) uint64 {
+	tr.RLock()
+	defer tr.RUnlock()
+	return tr.versions[accID]
+}
+
+// Ordered transactions of account and version of its index.
+func (tr *TransactionRegistry) snapshot(accID ID) (uint64, []Transaction) {
+	tr.RLock()
+	defer tr.RUnlock()
+	return tr.versions[accID], tr.slice(accID, 0, len(tr.index[accID]))
+}
+
+// Find the transaction of account preceding the given one (by time and sequence).
+func (tr *TransactionRegistry) Prev(accID, trID ID) *Transaction {
+	tr.RLock()
+	defer tr.RUnlock()
+
+	i := tr.position(accID, trID)
+	if i < 1 {
+		return nil
+	}
+	t := tr.items[tr.index[accID][i-1].id]
+	return &t
+}
+
+// Find all transactions of account following the given one (by time and sequence).
+func (tr *TransactionRegistry) Next(accID, trID ID) []Transaction {
+	tr.RLock()
+	defer tr.RUnlock()
+
+	i := tr.position(accID, trID)
+	if i < 0 {
+		return nil
+	}
+	return tr.slice(accID, i+1, len(tr.index[accID]))
+}
+
+// Find all transactions of account since given time (inclusive), sorted by time.
+func (tr *TransactionRegistry) Since(accID ID, trTime time.Time) []Transaction {
+	tr.RLock()
+	defer tr.RUnlock()
+	return tr.slice(accID, tr.search(accID, trTime), len(tr.index[accID]))
+}
+
+// Find the reversing entry of given transaction.
+func (tr *TransactionRegistry) VoidedBy(trID ID) *Transaction {
 	tr.RLock()
 	defer tr.RUnlock()
 
 	for _, t := range tr.items {
-		if (t.Source == accID || t.Dest == accID) && t.Time.After(trTime) {
-			trs = append(trs, t)
+		if !t.Deleted && t.Voids == trID {
+			return &t
 		}
 	}
-	return
+	return nil
 }
 
 // Delete all transaction of given account (useful in case of account deletion).
@@ -125,8 +294,10 @@ func (tr *TransactionRegistry) AllAfter(accID ID, trTime time.Time) (trs []Trans
 
 func CreateTransactionRegistry() *TransactionRegistry {
 	return &TransactionRegistry{
-		items:  make(map[ID]Transaction),
-		queued: make(map[ID]Transaction),
+		items:    make(map[ID]Transaction),
+		queued:   make(map[ID]Transaction),
+		index:    make(map[ID][]indexEntry),
+		versions: make(map[ID]uint64),
 	}
 }
 
@@ -0,0 +1,527 @@
+package miser
+
+import (
+	"fmt"
+	"math/rand/v2"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestUpdateTransaction(t *testing.T) {
+	t.Parallel()
+
+	// Create repositories:
+	ar := CreateAccountRegistry()
+	tr := CreateTransactionRegistry()
+	br := CreateBalanceRegistry()
+	cr := CreateCurrencyRegistry()
+	tg := CreateTagRegistry()
+	tm := CreateTagsMapRegistry()
+
+	// Create service:
+	l := CreateLedger(ar, br, tr, cr, tg, tm)
+
+	openedAt := time.Date(2024, time.October, 1, 15, 30, 0, 0, time.UTC)
+	wallet, err := l.CreateAccount("Cash", Asset, "wallet", "USD", openedAt, "200")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	bazaar, err := l.CreateAccount("Bazaar", Expense, "sunday bazaar", "USD", openedAt, "0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	market, err := l.CreateAccount("Market", Expense, "supermarket", "USD", openedAt, "0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dt1 := time.Date(2024, time.October, 20, 10, 0, 0, 0, time.UTC)
+	tr1, err := l.CreateTransaction(wallet.ID, bazaar.ID, dt1, "10", "carrot")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dt2 := time.Date(2024, time.October, 21, 10, 0, 0, 0, time.UTC)
+	tr2, err := l.CreateTransaction(wallet.ID, bazaar.ID, dt2, "20", "oranges")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	balance := func(accID, trID ID) string {
+		b := l.br.TransactionBalance(accID, trID)
+		if b == nil {
+			return "<nil>"
+		}
+		return b.Value.String()
+	}
+
+	t.Run("amount", func(t *testing.T) {
+		if _, err := l.UpdateTransaction(tr1.ID, wallet.ID, bazaar.ID, dt1, "15", "carrot, 3kg"); err != nil {
+			t.Fatal(err)
+		}
+
+		if s := balance(wallet.ID, tr1.ID); s != "185" {
+			t.Errorf("expected 185, got: %s", s)
+		}
+		if s := balance(wallet.ID, tr2.ID); s != "165" {
+			t.Errorf("expected 165, got: %s", s)
+		}
+		if s := balance(bazaar.ID, tr2.ID); s != "35" {
+			t.Errorf("expected 35, got: %s", s)
+		}
+
+		if transa := l.tr.Get(tr1.ID); transa.Text != "carrot, 3kg" || transa.Value.String() != "15" {
+			t.Errorf("transaction was not updated: %#v", transa)
+		}
+	})
+
+	t.Run("time", func(t *testing.T) {
+		// move the second transaction before the first one:
+		dt0 := time.Date(2024, time.October, 19, 10, 0, 0, 0, time.UTC)
+		if _, err := l.UpdateTransaction(tr2.ID, wallet.ID, bazaar.ID, dt0, "20", "oranges"); err != nil {
+			t.Fatal(err)
+		}
+
+		if s := balance(wallet.ID, tr2.ID); s != "180" {
+			t.Errorf("expected 180, got: %s", s)
+		}
+		if s := balance(wallet.ID, tr1.ID); s != "165" {
+			t.Errorf("expected 165, got: %s", s)
+		}
+		if s := balance(bazaar.ID, tr1.ID); s != "35" {
+			t.Errorf("expected 35, got: %s", s)
+		}
+	})
+
+	t.Run("accounts", func(t *testing.T) {
+		if _, err := l.UpdateTransaction(tr1.ID, wallet.ID, market.ID, dt1, "15", "carrot, 3kg"); err != nil {
+			t.Fatal(err)
+		}
+
+		if s := balance(bazaar.ID, tr1.ID); s != "<nil>" {
+			t.Errorf("expected obsolete balance to be deleted, got: %s", s)
+		}
+		if s := l.AccountBalance(bazaar.ID).Value.String(); s != "20" {
+			t.Errorf("expected 20 in bazaar, got: %s", s)
+		}
+		if s := l.AccountBalance(market.ID).Value.String(); s != "15" {
+			t.Errorf("expected 15 in market, got: %s", s)
+		}
+		if s := l.AccountBalance(wallet.ID).Value.String(); s != "165" {
+			t.Errorf("expected 165 in wallet, got: %s", s)
+		}
+	})
+
+	t.Run("validation", func(t *testing.T) {
+		// the wallet has 165 + 15 of updated transaction:
+		if _, err := l.UpdateTransaction(tr1.ID, wallet.ID, market.ID, dt1, "180.01", ""); err == nil {
+			t.Error("overdraft error expected, nil found")
+		}
+		if _, err := l.UpdateTransaction(tr1.ID, wallet.ID, market.ID, dt1, "180", ""); err != nil {
+			t.Errorf("unexpected error: %s", err)
+		}
+		if _, err := l.UpdateTransaction(tr1.ID, wallet.ID, market.ID, openedAt.Add(-time.Hour), "1", ""); err == nil {
+			t.Error("error expected for transaction before account is opened, nil found")
+		}
+		if _, err := l.UpdateTransaction(tr1.ID, bazaar.ID, market.ID, dt1, "1", ""); err == nil {
+			t.Error("error expected for the same type of accounts, nil found")
+		}
+		if _, err := l.UpdateTransaction(CreateID(), wallet.ID, market.ID, dt1, "1", ""); err == nil {
+			t.Error("error expected for unknown transaction, nil found")
+		}
+
+		initial := l.tr.FirstBefore(wallet.ID, dt1)
+		for initial != nil && !initial.IsInitial() {
+			initial = l.tr.FirstBefore(wallet.ID, initial.Time)
+		}
+		if _, err := l.UpdateTransaction(initial.ID, wallet.ID, market.ID, dt1, "1", ""); err == nil {
+			t.Error("error expected for initial transaction, nil found")
+		}
+	})
+}
+
+func TestDeleteTransaction(t *testing.T) {
+	t.Parallel()
+
+	// Create repositories:
+	ar := CreateAccountRegistry()
+	tr := CreateTransactionRegistry()
+	br := CreateBalanceRegistry()
+	cr := CreateCurrencyRegistry()
+	tg := CreateTagRegistry()
+	tm := CreateTagsMapRegistry()
+
+	// Create service:
+	l := CreateLedger(ar, br, tr, cr, tg, tm)
+
+	openedAt := time.Date(2024, time.October, 1, 15, 30, 0, 0, time.UTC)
+	wallet, err := l.CreateAccount("Cash", Asset, "wallet", "USD", openedAt, "200")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	bazaar, err := l.CreateAccount("Bazaar", Expense, "sunday bazaar", "USD", openedAt, "0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var trs []*Transaction
+	for i, v := range []string{"10", "20", "30"} {
+		dt := openedAt.AddDate(0, 0, i+1)
+		transa, err := l.CreateTransaction(wallet.ID, bazaar.ID, dt, v, "")
+		if err != nil {
+			t.Fatal(err)
+		}
+		trs = append(trs, transa)
+	}
+
+	if err := l.DeleteTransaction(trs[1].ID); err != nil {
+		t.Fatal(err)
+	}
+
+	if b := l.br.TransactionBalance(wallet.ID, trs[1].ID); b != nil {
+		t.Errorf("expected balance of deleted transaction to be deleted, got: %s", b.Value)
+	}
+	if s := l.br.TransactionBalance(wallet.ID, trs[2].ID).Value.String(); s != "160" {
+		t.Errorf("expected 160 in wallet after the last transaction, got: %s", s)
+	}
+	if s := l.AccountBalance(bazaar.ID).Value.String(); s != "40" {
+		t.Errorf("expected 40 in bazaar, got: %s", s)
+	}
+
+	if transa := l.tr.FirstBefore(wallet.ID, trs[2].Time); transa == nil || transa.ID != trs[0].ID {
+		t.Errorf("expected deleted transaction to be skipped, got: %#v", transa)
+	}
+	for _, transa := range l.tr.AllAfter(wallet.ID, trs[0].Time) {
+		if transa.ID == trs[1].ID {
+			t.Error("expected deleted transaction to be skipped")
+		}
+	}
+
+	// delete the last one:
+	if err := l.DeleteTransaction(trs[2].ID); err != nil {
+		t.Fatal(err)
+	}
+	if transa := l.tr.Last(wallet.ID); transa == nil || transa.ID != trs[0].ID {
+		t.Errorf("expected the first transaction to be the last, got: %#v", transa)
+	}
+	if s := l.AccountBalance(wallet.ID).Value.String(); s != "190" {
+		t.Errorf("expected 190 in wallet, got: %s", s)
+	}
+
+	if err := l.DeleteTransaction(trs[2].ID); err == nil {
+		t.Error("error expected for already deleted transaction, nil found")
+	}
+	if _, err := l.UpdateTransaction(trs[2].ID, wallet.ID, bazaar.ID, trs[2].Time, "1", ""); err == nil {
+		t.Error("error expected for update of deleted transaction, nil found")
+	}
+}
+
+func TestVoidTransaction(t *testing.T) {
+	t.Parallel()
+
+	// Create repositories:
+	ar := CreateAccountRegistry()
+	tr := CreateTransactionRegistry()
+	br := CreateBalanceRegistry()
+	cr := CreateCurrencyRegistry()
+	tg := CreateTagRegistry()
+	tm := CreateTagsMapRegistry()
+
+	// Create service:
+	l := CreateLedger(ar, br, tr, cr, tg, tm)
+
+	openedAt := time.Date(2024, time.October, 1, 15, 30, 0, 0, time.UTC)
+	wallet, err := l.CreateAccount("Cash", Asset, "wallet", "USD", openedAt, "200")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	bazaar, err := l.CreateAccount("Bazaar", Expense, "sunday bazaar", "USD", openedAt, "0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dt := openedAt.AddDate(0, 0, 1)
+	orig, err := l.CreateTransaction(wallet.ID, bazaar.ID, dt, "25.50", "double charge")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := l.VoidTransaction(orig.ID, dt); err == nil {
+		t.Error("error expected for reversing entry at time of voided transaction, nil found")
+	}
+
+	rev, err := l.VoidTransaction(orig.ID, dt.Add(time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if rev.Voids != orig.ID || rev.Source != bazaar.ID || rev.Dest != wallet.ID || !rev.Value.Equal(orig.Value) {
+		t.Errorf("unexpected reversing entry: %#v", rev)
+	}
+
+	if transa := l.tr.Get(orig.ID); transa.Deleted {
+		t.Error("voided transaction should stay untouched")
+	}
+
+	if s := l.AccountBalance(wallet.ID).Value.String(); s != "200" {
+		t.Errorf("expected 200 in wallet, got: %s", s)
+	}
+	if s := l.AccountBalance(bazaar.ID).Value.String(); s != "0" {
+		t.Errorf("expected 0 in bazaar, got: %s", s)
+	}
+
+	if _, err := l.VoidTransaction(orig.ID, dt.Add(2*time.Hour)); err == nil {
+		t.Error("error expected for already voided transaction, nil found")
+	}
+	if _, err := l.VoidTransaction(rev.ID, dt.Add(2*time.Hour)); err == nil {
+		t.Error("error expected for void of reversing entry, nil found")
+	}
+
+	// the history of voided transaction is immutable:
+	for _, id := range []ID{orig.ID, rev.ID} {
+		if err := l.DeleteTransaction(id); err == nil {
+			t.Errorf("error expected for deletion of %s, nil found", id)
+		}
+		if _, err := l.UpdateTransaction(id, wallet.ID, bazaar.ID, dt, "10", ""); err == nil {
+			t.Errorf("error expected for update of %s, nil found", id)
+		}
+	}
+	if s := l.AccountBalance(wallet.ID).Value.String(); s != "200" {
+		t.Errorf("expected 200 in wallet, got: %s", s)
+	}
+}
+
+// Naive full scans, which the index of transactions replaced:
+
+func scanLast(tr *TransactionRegistry, accID ID) *Transaction {
+	var transa *Transaction
+	for _, t := range tr.items {
+		if !t.Deleted && (t.Source == accID || t.Dest == accID) && (transa == nil || t.Time.After(transa.Time)) {
+			transa = &t
+		}
+	}
+	return transa
+}
+
+func scanFirstBefore(tr *TransactionRegistry, accID ID, trTime time.Time) *Transaction {
+	var transa *Transaction
+	for _, t := range tr.items {
+		if !t.Deleted && (t.Source == accID || t.Dest == accID) && t.Time.Before(trTime) &&
+			(transa == nil || t.Time.After(transa.Time)) {
+			transa = &t
+		}
+	}
+	return transa
+}
+
+func scanAllAfter(tr *TransactionRegistry, accID ID, trTime time.Time) (trs []Transaction) {
+	for _, t := range tr.items {
+		if !t.Deleted && (t.Source == accID || t.Dest == accID) && t.Time.After(trTime) {
+			trs = append(trs, t)
+		}
+	}
+	return
+}
+
+// Registry of n transactions between given number of accounts at random times of 10 years.
+func randomTransactions(n, accounts int) (*TransactionRegistry, []ID) {
+	r := rand.New(rand.NewPCG(1, 2))
+	start := time.Date(2015, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+	accIDs := make([]ID, accounts)
+	for i := range accIDs {
+		accIDs[i] = ID(fmt.Sprintf("acc%03d", i))
+	}
+
+	tr := CreateTransactionRegistry()
+	for i := range n {
+		tr.Add(Transaction{
+			ID:     ID(fmt.Sprintf("tr%06d", i)),
+			Source: accIDs[r.IntN(accounts)],
+			Dest:   accIDs[r.IntN(accounts)],
+			Time:   start.Add(time.Duration(r.Int64N(int64(10 * 365 * 24 * time.Hour)))),
+			Value:  AmountFromInt(int64(r.IntN(1000) + 1)),
+		})
+	}
+	return tr, accIDs
+}
+
+func TestTransactionIndex(t *testing.T) {
+	t.Parallel()
+
+	tr, accIDs := randomTransactions(5000, 10)
+
+	// move, delete and restore some transactions to check the index maintenance:
+	for i := 0; i < 5000; i += 7 {
+		transa := *tr.Get(ID(fmt.Sprintf("tr%06d", i)))
+		transa.Time = transa.Time.AddDate(0, -3, 0)
+		transa.Source, transa.Dest = transa.Dest, accIDs[i%len(accIDs)]
+		tr.Add(transa)
+
+		transa.Deleted = i%2 == 0
+		tr.Add(transa)
+	}
+
+	byTime := func(a, b Transaction) int { return a.Time.Compare(b.Time) }
+	at := time.Date(2020, time.June, 1, 0, 0, 0, 0, time.UTC)
+
+	for _, accID := range accIDs {
+		if got, want := tr.Last(accID), scanLast(tr, accID); !got.Time.Equal(want.Time) {
+			t.Errorf("last of %s: expected %s, got: %s", accID, want.Time, got.Time)
+		}
+
+		if got, want := tr.FirstBefore(accID, at), scanFirstBefore(tr, accID, at); !got.Time.Equal(want.Time) {
+			t.Errorf("first before of %s: expected %s, got: %s", accID, want.Time, got.Time)
+		}
+
+		got, want := tr.AllAfter(accID, at), scanAllAfter(tr, accID, at)
+		slices.SortFunc(want, byTime)
+		if !slices.IsSortedFunc(got, byTime) {
+			t.Errorf("all after of %s: transactions are not sorted by time", accID)
+		}
+		if len(got) != len(want) {
+			t.Errorf("all after of %s: expected %d transactions, got: %d", accID, len(want), len(got))
+		}
+	}
+
+	if tr.Last("unknown") != nil || tr.FirstBefore(accIDs[0], time.Time{}) != nil {
+		t.Error("expected no transactions")
+	}
+}
+
+func BenchmarkTransactionIndex(b *testing.B) {
+	tr, accIDs := randomTransactions(100_000, 100)
+	at := time.Date(2024, time.June, 1, 0, 0, 0, 0, time.UTC)
+
+	b.Run("Last/index", func(b *testing.B) {
+		for i := range b.N {
+			tr.Last(accIDs[i%len(accIDs)])
+		}
+	})
+	b.Run("Last/scan", func(b *testing.B) {
+		for i := range b.N {
+			scanLast(tr, accIDs[i%len(accIDs)])
+		}
+	})
+
+	b.Run("FirstBefore/index", func(b *testing.B) {
+		for i := range b.N {
+			tr.FirstBefore(accIDs[i%len(accIDs)], at)
+		}
+	})
+	b.Run("FirstBefore/scan", func(b *testing.B) {
+		for i := range b.N {
+			scanFirstBefore(tr, accIDs[i%len(accIDs)], at)
+		}
+	})
+
+	b.Run("AllAfter/index", func(b *testing.B) {
+		for i := range b.N {
+			tr.AllAfter(accIDs[i%len(accIDs)], at)
+		}
+	})
+	b.Run("AllAfter/scan", func(b *testing.B) {
+		for i := range b.N {
+			scanAllAfter(tr, accIDs[i%len(accIDs)], at)
+		}
+	})
+}
+
+func TestSameTimeOrder(t *testing.T) {
+	t.Parallel()
+
+	// Create repositories:
+	ar := CreateAccountRegistry()
+	tr := CreateTransactionRegistry()
+	br := CreateBalanceRegistry()
+	cr := CreateCurrencyRegistry()
+	tg := CreateTagRegistry()
+	tm := CreateTagsMapRegistry()
+
+	// Create service:
+	l := CreateLedger(ar, br, tr, cr, tg, tm)
+
+	openedAt := time.Date(2024, time.October, 1, 0, 0, 0, 0, time.UTC)
+	wallet, err := l.CreateAccount("Cash", Asset, "wallet", "USD", openedAt, "100")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	bazaar, err := l.CreateAccount("Bazaar", Expense, "sunday bazaar", "USD", openedAt, "0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// date-only import: all transactions at midnight, even at time of the initial ones
+	var ids []ID
+	for _, v := range []string{"30", "50", "10"} {
+		transa, err := l.CreateTransaction(wallet.ID, bazaar.ID, openedAt, v, "")
+		if err != nil {
+			t.Fatal(err)
+		}
+		ids = append(ids, transa.ID)
+	}
+
+	balances := func() (values []string) {
+		for _, id := range ids {
+			values = append(values, l.br.TransactionBalance(wallet.ID, id).Value.String())
+		}
+		return
+	}
+
+	if got, want := balances(), []string{"70", "20", "10"}; !slices.Equal(got, want) {
+		t.Errorf("expected running balances in insertion order %v, got: %v", want, got)
+	}
+
+	if err := l.ReorderTransactions(ids[2], ids[0], ids[1]); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := balances(), []string{"60", "10", "90"}; !slices.Equal(got, want) {
+		t.Errorf("expected running balances after reorder %v, got: %v", want, got)
+	}
+	if s := l.AccountBalance(wallet.ID).Value.String(); s != "10" {
+		t.Errorf("expected 10 in wallet, got: %s", s)
+	}
+	if last := l.tr.Last(wallet.ID); last.ID != ids[1] {
+		t.Errorf("expected the last transaction to be reordered one, got: %s", last.Value)
+	}
+
+	later, err := l.CreateTransaction(wallet.ID, bazaar.ID, openedAt.Add(time.Hour), "5", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := l.ReorderTransactions(ids[0], later.ID); err == nil {
+		t.Error("error expected for reorder of transactions of different time, nil found")
+	}
+
+	initial := l.tr.Since(wallet.ID, openedAt)[0]
+	if !initial.IsInitial() {
+		t.Fatalf("expected the initial transaction to be the first one, got: %#v", initial)
+	}
+	if err := l.ReorderTransactions(ids[0], initial.ID); err == nil {
+		t.Error("error expected for reorder of initial transaction, nil found")
+	}
+	if s := l.AccountBalance(wallet.ID).Value.String(); s != "5" {
+		t.Errorf("expected 5 in wallet, got: %s", s)
+	}
+
+	t.Run("legacy", func(t *testing.T) {
+		// transactions of journal without sequence get it in order of loading:
+		tr := CreateTransactionRegistry()
+		tr.Add(Transaction{ID: "b", Source: "acc", Dest: "acc", Time: openedAt})
+		tr.Add(Transaction{ID: "a", Source: "acc", Dest: "acc", Time: openedAt})
+		tr.Add(Transaction{ID: "b", Source: "acc", Dest: "acc", Time: openedAt, Text: "updated"})
+
+		if trs := tr.Since("acc", openedAt); len(trs) != 2 || trs[0].ID != "b" || trs[0].Text != "updated" {
+			t.Errorf("unexpected order of legacy transactions: %v", trs)
+		}
+		if seq := tr.NextSeq(); seq != 3 {
+			t.Errorf("expected the next sequence 3, got: %d", seq)
+		}
+	})
+}
@@ -0,0 +1,118 @@
+package miser
+
+import (
+	"cmp"
+	"slices"
+	"time"
+)
+
+// TrialBalance is a report of balances of all accounts as of a date with the check
+// of the rearranged accounting equation: Assets + Expenses = Liabilities + Equity + Income.
+// Values are summed as is regardless of currency: transactions move the same value between accounts.
+type TrialBalance struct {
+	Date      time.Time
+	Lines     []TrialBalanceLine
+	Totals    map[string]Amount // by account type
+	Imbalance Amount            // Assets + Expenses - Liabilities - Equity - Income
+	Culprits  []Culprit         // responsible for imbalance, the sum of their amounts is equal to it
+}
+
+// TrialBalanceLine is the last balance of account as of the date.
+type TrialBalanceLine struct {
+	Account ID
+	Type    string
+	Balance Amount
+}
+
+// Culprit is a transaction or an account (Transaction is empty) which breaks the equation.
+type Culprit struct {
+	Account, Transaction ID
+	Amount               Amount // contribution to imbalance
+	Reason               string
+}
+
+func (tb *TrialBalance) Balanced() bool { return tb.Imbalance.IsZero() }
+
+// Contribution of balance of account type to the left side of the equation.
+func equationSide(accType string, v Amount) Amount {
+	if accType == Asset || accType == Expense {
+		return v
+	}
+	return v.Neg()
+}
+
+// Trial balance as of the end of given date.
+func (l *Ledger) TrialBalance(date time.Time) *TrialBalance {
+	tb := &TrialBalance{Date: date, Totals: make(map[string]Amount)}
+	end := nextDay(date)
+
+	for _, acc := range l.ar.List() {
+		if !acc.OpenedAt.Before(end) {
+			continue
+		}
+
+		var balance Amount
+		last := l.tr.FirstBefore(acc.ID, end)
+		if last != nil {
+			if b := l.TransactionBalance(acc.ID, last.ID); b != nil {
+				balance = b.Value
+			}
+		}
+
+		accType := string(acc.Type)
+		tb.Lines = append(tb.Lines, TrialBalanceLine{Account: acc.ID, Type: accType, Balance: balance})
+		tb.Totals[accType] = tb.Totals[accType].Add(balance)
+		tb.Imbalance = tb.Imbalance.Add(equationSide(accType, balance))
+		tb.Culprits = append(tb.Culprits, l.culprits(&acc, last, balance, end)...)
+	}
+
+	if tb.Balanced() {
+		tb.Culprits = nil
+	}
+
+	slices.SortFunc(tb.Lines, func(a, b TrialBalanceLine) int {
+		if c := cmp.Compare(a.Type, b.Type); c != 0 {
+			return c
+		}
+		return cmp.Compare(a.Account, b.Account)
+	})
+	slices.SortFunc(tb.Culprits, func(a, b Culprit) int {
+		if c := cmp.Compare(a.Account, b.Account); c != 0 {
+			return c
+		}
+		return cmp.Compare(a.Transaction, b.Transaction)
+	})
+	return tb
+}
+
+// Find what breaks the equation in account up to given time:
+// initial balances posted without counterpart and balances of journal
+// which differ from the ones computed from transactions.
+func (l *Ledger) culprits(acc *Account, last *Transaction, balance Amount, end time.Time) (found []Culprit) {
+	accType := string(acc.Type)
+
+	for _, t := range l.tr.Since(acc.ID, time.Time{}) {
+		if !t.Time.Before(end) {
+			break
+		}
+		if t.IsInitial() && !t.Value.IsZero() {
+			found = append(found, Culprit{
+				Account: acc.ID, Transaction: t.ID,
+				Amount: equationSide(accType, t.Value),
+				Reason: "initial balance has no counterpart",
+			})
+		}
+	}
+
+	if last == nil {
+		return
+	}
+	if derived, ok := l.derivedBalance(acc.ID, last.ID); ok && !derived.Equal(balance) {
+		found = append(found, Culprit{
+			Account: acc.ID,
+			Amount:  equationSide(accType, balance.Sub(derived)),
+			Reason:  "balance differs from the one computed from transactions",
+		})
+	}
+	return
+}
@@ -0,0 +1,107 @@
+package miser
+
+import (
+	"testing"
+	"time"
+)
+
+func TestTrialBalance(t *testing.T) {
+	t.Parallel()
+
+	// Create repositories:
+	ar := CreateAccountRegistry()
+	tr := CreateTransactionRegistry()
+	br := CreateBalanceRegistry()
+	cr := CreateCurrencyRegistry()
+	tg := CreateTagRegistry()
+	tm := CreateTagsMapRegistry()
+
+	// Create service:
+	l := CreateLedger(ar, br, tr, cr, tg, tm)
+
+	openedAt := time.Date(2024, time.January, 1, 9, 0, 0, 0, time.UTC)
+	bank, err := l.CreateAccount("Bank", Asset, "checking", "USD", openedAt, "1000")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := l.CreateAccount("Opening", Equity, "opening balances", "USD", openedAt, "1000"); err != nil {
+		t.Fatal(err)
+	}
+	shop, err := l.CreateAccount("Shop", Expense, "groceries", "USD", openedAt, "0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	day := openedAt.AddDate(0, 0, 10)
+	transa, err := l.CreateTransaction(bank.ID, shop.ID, day, "100", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tb := l.TrialBalance(day)
+	if !tb.Balanced() || len(tb.Culprits) != 0 {
+		t.Errorf("expected balanced trial balance, imbalance: %s, culprits: %v", tb.Imbalance, tb.Culprits)
+	}
+	if s := tb.Totals[Asset].String(); s != "900" {
+		t.Errorf("expected 900 of assets, got: %s", s)
+	}
+	if s := tb.Totals[Expense].String(); s != "100" {
+		t.Errorf("expected 100 of expenses, got: %s", s)
+	}
+	if n := len(tb.Lines); n != 3 {
+		t.Errorf("expected 3 accounts, got: %d", n)
+	}
+
+	if s := l.TrialBalance(day.AddDate(0, 0, -1)).Totals[Expense].String(); s != "0" {
+		t.Errorf("expected no expenses before transaction, got: %s", s)
+	}
+
+	card, err := l.CreateAccount("Card", Liability, "credit card", "USD", openedAt, "300")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tb = l.TrialBalance(day)
+	if s := tb.Imbalance.String(); s != "-300" {
+		t.Errorf("expected imbalance -300, got: %s", s)
+	}
+	if !culpritsExplain(tb) {
+		t.Errorf("culprits do not explain imbalance %s: %v", tb.Imbalance, tb.Culprits)
+	}
+	if !hasCulprit(tb, card.ID, "-300") {
+		t.Errorf("expected initial balance of card as culprit, got: %v", tb.Culprits)
+	}
+
+	t.Run("journal", func(t *testing.T) {
+		l.CreateBalance(shop.ID, transa.ID, MustParseAmount("150"))
+
+		tb := l.TrialBalance(day)
+		if s := tb.Imbalance.String(); s != "-250" {
+			t.Errorf("expected imbalance -250, got: %s", s)
+		}
+		if !culpritsExplain(tb) {
+			t.Errorf("culprits do not explain imbalance %s: %v", tb.Imbalance, tb.Culprits)
+		}
+		if !hasCulprit(tb, shop.ID, "50") {
+			t.Errorf("expected broken balance of shop as culprit, got: %v", tb.Culprits)
+		}
+	})
+}
+
+// Check if the sum of culprits contributions is equal to imbalance.
+func culpritsExplain(tb *TrialBalance) bool {
+	var sum Amount
+	for _, c := range tb.Culprits {
+		sum = sum.Add(c.Amount)
+	}
+	return sum.Equal(tb.Imbalance)
+}
+
+func hasCulprit(tb *TrialBalance, accID ID, amount string) bool {
+	for _, c := range tb.Culprits {
+		if c.Account == accID && c.Amount.String() == amount {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,74 @@
+package miser
+
+import (
+	"cmp"
+	"slices"
+)
+
+// Kinds of discrepancies between the balance journal and transactions.
+const (
+	MissingBalance = iota // no balance of account transaction in the journal
+	StaleBalance          // the balance differs from the replayed one
+	OrphanBalance         // the balance of deleted transaction or of account which is not a part of it
+)
+
+// BalanceDiscrepancy is a balance of the journal which does not match the replay of transactions.
+type BalanceDiscrepancy struct {
+	Kind                 int
+	Account, Transaction ID
+	Journal, Expected    Amount
+}
+
+// Verify the balance journal: replay all transactions through the double-entry rules
+// and compare the running balances with the journal ones.
+func (l *Ledger) VerifyBalances() (found []BalanceDiscrepancy) {
+	for accID := range l.ar.List() {
+		d := l.derive(accID)
+		if d == nil {
+			continue
+		}
+		for trID, i := range d.pos {
+			b := l.br.TransactionBalance(accID, trID)
+			switch {
+			case b == nil:
+				found = append(found, BalanceDiscrepancy{
+					Kind: MissingBalance, Account: accID, Transaction: trID, Expected: d.values[i]})
+			case !b.Value.Equal(d.values[i]):
+				found = append(found, BalanceDiscrepancy{
+					Kind: StaleBalance, Account: accID, Transaction: trID, Journal: b.Value, Expected: d.values[i]})
+			}
+		}
+	}
+
+	for _, b := range l.br.List() {
+		if b.Deleted {
+			continue
+		}
+		if _, ok := l.derivedBalance(b.Account, b.Transaction); !ok {
+			found = append(found, BalanceDiscrepancy{
+				Kind: OrphanBalance, Account: b.Account, Transaction: b.Transaction, Journal: b.Value})
+		}
+	}
+
+	slices.SortFunc(found, func(a, b BalanceDiscrepancy) int {
+		if c := cmp.Compare(a.Account, b.Account); c != 0 {
+			return c
+		}
+		return cmp.Compare(a.Transaction, b.Transaction)
+	})
+	return
+}
+
+// Repair the balance journal: write corrected versions of balances found
+// by the verification, orphan balances are marked as deleted.
+func (l *Ledger) RepairBalances() (fixed []BalanceDiscrepancy) {
+	fixed = l.VerifyBalances()
+	for _, d := range fixed {
+		if d.Kind == OrphanBalance {
+			l.DeleteBalance(d.Account, d.Transaction)
+		} else {
+			l.CreateBalance(d.Account, d.Transaction, d.Expected)
+		}
+	}
+	return
+}
@@ -0,0 +1,78 @@
+package miser
+
+import (
+	"testing"
+	"time"
+)
+
+func TestVerifyBalances(t *testing.T) {
+	t.Parallel()
+
+	// Create repositories:
+	ar := CreateAccountRegistry()
+	tr := CreateTransactionRegistry()
+	br := CreateBalanceRegistry()
+	cr := CreateCurrencyRegistry()
+	tg := CreateTagRegistry()
+	tm := CreateTagsMapRegistry()
+
+	// Create service:
+	l := CreateLedger(ar, br, tr, cr, tg, tm)
+
+	openedAt := time.Date(2024, time.January, 1, 9, 0, 0, 0, time.UTC)
+	bank, err := l.CreateAccount("Bank", Asset, "checking", "USD", openedAt, "1000")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	shop, err := l.CreateAccount("Shop", Expense, "groceries", "USD", openedAt, "0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var trs []*Transaction
+	for i, v := range []string{"100", "200", "300"} {
+		transa, err := l.CreateTransaction(bank.ID, shop.ID, openedAt.AddDate(0, 0, i+1), v, "")
+		if err != nil {
+			t.Fatal(err)
+		}
+		trs = append(trs, transa)
+	}
+
+	if found := l.VerifyBalances(); len(found) != 0 {
+		t.Fatalf("expected consistent balances, got: %v", found)
+	}
+
+	// drift the journal apart from transactions:
+	l.CreateBalance(bank.ID, trs[1].ID, MustParseAmount("777"))
+	delete(br.items, Balance{Account: shop.ID, Transaction: trs[2].ID}.ID())
+	l.CreateBalance(shop.ID, "ghost", MustParseAmount("1"))
+
+	found := l.VerifyBalances()
+	kinds := make(map[int]BalanceDiscrepancy)
+	for _, d := range found {
+		kinds[d.Kind] = d
+	}
+	if len(found) != 3 || len(kinds) != 3 {
+		t.Fatalf("expected missing, stale and orphan balances, got: %v", found)
+	}
+	if d := kinds[StaleBalance]; d.Transaction != trs[1].ID || d.Expected.String() != "700" || d.Journal.String() != "777" {
+		t.Errorf("unexpected stale balance: %v", d)
+	}
+	if d := kinds[MissingBalance]; d.Transaction != trs[2].ID || d.Expected.String() != "600" {
+		t.Errorf("unexpected missing balance: %v", d)
+	}
+	if d := kinds[OrphanBalance]; d.Transaction != "ghost" {
+		t.Errorf("unexpected orphan balance: %v", d)
+	}
+
+	if fixed := l.RepairBalances(); len(fixed) != 3 {
+		t.Errorf("expected 3 fixed balances, got: %d", len(fixed))
+	}
+	if found := l.VerifyBalances(); len(found) != 0 {
+		t.Errorf("expected consistent balances after repair, got: %v", found)
+	}
+	if s := l.AccountBalance(shop.ID).Value.String(); s != "600" {
+		t.Errorf("expected 600 of shop, got: %s", s)
+	}
+}